@@ -4,9 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	platformcache "gitlab.com/zynero/shared/cache"
+	platformconfig "gitlab.com/zynero/shared/config"
 	platformdatabase "gitlab.com/zynero/shared/database"
 	platformgrpc "gitlab.com/zynero/shared/grpc"
 	platformhealthcheck "gitlab.com/zynero/shared/healthcheck"
@@ -14,6 +22,7 @@ import (
 	platformmetrics "gitlab.com/zynero/shared/metrics"
 	platformserver "gitlab.com/zynero/shared/server"
 	"gitlab.com/zynero/shared/transport/kafka"
+	"golang.org/x/sync/errgroup"
 )
 
 // ConfigProvider describes configuration required to bootstrap common
@@ -24,21 +33,92 @@ type ConfigProvider interface {
 	LoggerConfig() platformlogger.Config
 }
 
-// OptionalConfigProvider describes optional configuration methods that may not be implemented
-// by all services. These methods should return nil if the component is not needed.
-type OptionalConfigProvider interface {
+// MetricsConfigProvider is implemented by configurations enabling metrics.
+type MetricsConfigProvider interface {
 	MetricsConfig() *platformmetrics.Config
+}
+
+// HealthcheckConfigProvider is implemented by configurations enabling healthcheck.
+type HealthcheckConfigProvider interface {
 	HealthcheckConfig() *platformhealthcheck.Config
+}
+
+// ServerConfigProvider is implemented by configurations enabling the HTTP server.
+type ServerConfigProvider interface {
 	ServerConfig() *platformserver.Config
+}
+
+// DatabaseConfigProvider is implemented by configurations enabling the database.
+type DatabaseConfigProvider interface {
 	DatabaseConfig() *platformdatabase.Config
+}
+
+// CacheConfigProvider is implemented by configurations enabling the cache.
+type CacheConfigProvider interface {
 	CacheConfig() *platformcache.Config
+}
+
+// KafkaConfigProvider is implemented by configurations enabling Kafka.
+type KafkaConfigProvider interface {
 	KafkaConfig() *kafka.Config
+}
+
+// GRPCConfigProvider is implemented by configurations enabling the gRPC server.
+type GRPCConfigProvider interface {
 	GRPCConfig() *platformgrpc.Config
 }
 
+// OptionalConfigProvider combines all optional configuration methods. Services
+// only need to implement the single-method providers for the components they
+// use. These methods should return nil if the component is not needed.
+type OptionalConfigProvider interface {
+	MetricsConfigProvider
+	HealthcheckConfigProvider
+	ServerConfigProvider
+	DatabaseConfigProvider
+	CacheConfigProvider
+	KafkaConfigProvider
+	GRPCConfigProvider
+}
+
+// ErrLoggerRequired is reported by Build for a configured gRPC server, whose
+// interceptors log through the application logger, when the logger could not
+// be initialized.
+var ErrLoggerRequired = errors.New("logger is required")
+
+// DefaultShutdownTimeout bounds application shutdown in Run when
+// App.ShutdownTimeout is not set.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ApplicationInfo identifies the running service. When set on the builder, its
+// fields are attached to every log entry of the application logger.
+type ApplicationInfo struct {
+	Name    string
+	Version string
+}
+
+// fields returns the log fields describing the application.
+func (i ApplicationInfo) fields() map[string]any {
+	fields := make(map[string]any, 2)
+	if i.Name != "" {
+		fields["service"] = i.Name
+	}
+	if i.Version != "" {
+		fields["version"] = i.Version
+	}
+	return fields
+}
+
 // App contains initialized shared components used across applications.
 // Only Logger is guaranteed to be present, other components may be nil.
+// Config is the configuration the application was built with; Reload does
+// not change it, use CurrentConfig for the reloaded configuration.
 type App struct {
+	// ShutdownTimeout bounds Close when triggered by Run. Zero means
+	// DefaultShutdownTimeout.
+	ShutdownTimeout time.Duration
+
+	Info           ApplicationInfo
 	Config         ConfigProvider
 	Logger         *platformlogger.Logger
 	Metrics        *platformmetrics.Metrics
@@ -48,11 +128,22 @@ type App struct {
 	Database       *platformdatabase.Database
 	Cache          platformcache.Cache
 	EventPublisher *kafka.KafkaEventPublisher
+
+	reloadMu    sync.Mutex
+	reloadHooks []func(cfg ConfigProvider) error
+	// reloaded holds the configuration applied by the last Reload.
+	reloaded atomic.Pointer[ConfigProvider]
+
+	// serversStopped is set by whichever of Start and Close stops the HTTP
+	// and gRPC servers first, so their shutdown hooks and PreShutdownDelay
+	// run once.
+	serversStopped atomic.Bool
 }
 
 // AppBuilder provides a fluent interface for building App instances
 type AppBuilder struct {
 	config         ConfigProvider
+	info           ApplicationInfo
 	logger         *platformlogger.Logger
 	metrics        *platformmetrics.Metrics
 	healthcheck    *platformhealthcheck.Healthcheck
@@ -62,51 +153,107 @@ type AppBuilder struct {
 	cache          platformcache.Cache
 	eventPublisher *kafka.KafkaEventPublisher
 	errors         []error
+	loggerFailed   bool
+
+	// steps holds requested optional components in request order. They are
+	// initialized by BuildContext so that a startup deadline applies to them.
+	steps     []initStep
+	requested map[string]bool
+}
+
+// initStep is a deferred initialization of an optional component.
+type initStep struct {
+	name string
+	run  func(ctx context.Context) error
 }
 
 // NewBuilder creates a new AppBuilder with the given configuration
 func NewBuilder(cfg ConfigProvider) *AppBuilder {
 	return &AppBuilder{
-		config: cfg,
-		errors: make([]error, 0),
+		config:    cfg,
+		errors:    checkOptionalMethods(cfg),
+		requested: make(map[string]bool),
 	}
 }
 
-// initOptionalComponent initializes optional component based on configuration
-// provided by OptionalConfigProvider. It appends initialization errors to the
-// builder and logs successful initialization.
-func initOptionalComponent[T any, C any](b *AppBuilder, field *T, getCfg func(OptionalConfigProvider) *C, initFn func(C) (T, error), name, successMsg string) {
-	optCfg, ok := b.config.(OptionalConfigProvider)
-	if !ok {
-		return
+// checkOptionalMethods reports optional config methods declared with a
+// signature that differs from OptionalConfigProvider. Such methods never
+// satisfy the interface, so the component would silently stay disabled.
+func checkOptionalMethods(cfg ConfigProvider) []error {
+	errs := make([]error, 0)
+	if cfg == nil {
+		return errs
 	}
 
-	cfg := getCfg(optCfg)
-	if cfg == nil {
-		return
+	iface := reflect.TypeOf((*OptionalConfigProvider)(nil)).Elem()
+	value := reflect.ValueOf(cfg)
+	for i := 0; i < iface.NumMethod(); i++ {
+		want := iface.Method(i)
+		method := value.MethodByName(want.Name)
+		if !method.IsValid() {
+			continue
+		}
+		if got := method.Type(); got != want.Type {
+			errs = append(errs, fmt.Errorf("config method %s has signature %s, want %s", want.Name, got, want.Type))
+		}
 	}
+	return errs
+}
 
-	component, err := initFn(*cfg)
-	if err != nil {
-		b.errors = append(b.errors, fmt.Errorf("init %s: %w", name, err))
+// addOptionalComponent schedules initialization of an optional component based
+// on configuration provided by the component specific provider P. Repeated
+// requests for the same component are ignored. The component is initialized
+// by BuildContext, which logs successful initialization.
+func addOptionalComponent[P any, T any, C any](b *AppBuilder, field *T, getCfg func(P) *C, initFn func(context.Context, C) (T, error), name, successMsg string) {
+	if b.requested[name] {
 		return
 	}
+	b.requested[name] = true
+
+	b.steps = append(b.steps, initStep{name: name, run: func(ctx context.Context) error {
+		provider, ok := any(b.config).(P)
+		if !ok {
+			return nil
+		}
+
+		cfg := getCfg(provider)
+		if cfg == nil {
+			return nil
+		}
+
+		component, err := initFn(ctx, *cfg)
+		if err != nil {
+			return err
+		}
 
-	*field = component
-	platformlogger.Info().Msg(successMsg)
+		*field = component
+		platformlogger.Info().Msg(successMsg)
+		return nil
+	}})
+}
+
+// WithApplicationInfo sets the service name and version attached to log
+// entries. It must be called before the logger is initialized.
+func (b *AppBuilder) WithApplicationInfo(info ApplicationInfo) *AppBuilder {
+	b.info = info
+	return b
 }
 
 // WithLogger initializes the logger (required component)
 func (b *AppBuilder) WithLogger() *AppBuilder {
-	if b.logger != nil {
+	if b.logger != nil || b.loggerFailed {
 		return b
 	}
 
 	logger, err := platformlogger.New(b.config.LoggerConfig())
 	if err != nil {
+		b.loggerFailed = true
 		b.errors = append(b.errors, fmt.Errorf("init logger: %w", err))
 		return b
 	}
+	if fields := b.info.fields(); len(fields) > 0 {
+		logger = logger.WithFields(fields)
+	}
 
 	platformlogger.SetGlobal(logger)
 	b.logger = logger
@@ -114,82 +261,71 @@ func (b *AppBuilder) WithLogger() *AppBuilder {
 	return b
 }
 
-// WithMetrics initializes metrics if configuration is provided
+// WithMetrics initializes metrics during Build if configuration is provided
 func (b *AppBuilder) WithMetrics() *AppBuilder {
-	if b.metrics != nil {
-		return b
-	}
-	initOptionalComponent(b, &b.metrics, func(o OptionalConfigProvider) *platformmetrics.Config { return o.MetricsConfig() }, func(cfg platformmetrics.Config) (*platformmetrics.Metrics, error) {
+	addOptionalComponent(b, &b.metrics, func(o MetricsConfigProvider) *platformmetrics.Config { return o.MetricsConfig() }, func(_ context.Context, cfg platformmetrics.Config) (*platformmetrics.Metrics, error) {
 		return platformmetrics.New(cfg)
 	}, "metrics", "Metrics initialized")
 	return b
 }
 
-// WithHealthcheck initializes healthcheck if configuration is provided
+// WithHealthcheck initializes healthcheck during Build if configuration is provided
 func (b *AppBuilder) WithHealthcheck() *AppBuilder {
-	if b.healthcheck != nil {
-		return b
-	}
-	initOptionalComponent(b, &b.healthcheck, func(o OptionalConfigProvider) *platformhealthcheck.Config { return o.HealthcheckConfig() }, func(cfg platformhealthcheck.Config) (*platformhealthcheck.Healthcheck, error) {
+	addOptionalComponent(b, &b.healthcheck, func(o HealthcheckConfigProvider) *platformhealthcheck.Config { return o.HealthcheckConfig() }, func(_ context.Context, cfg platformhealthcheck.Config) (*platformhealthcheck.Healthcheck, error) {
 		return platformhealthcheck.New(cfg)
 	}, "healthcheck", "Healthcheck initialized")
 	return b
 }
 
-// WithServer initializes HTTP server if configuration is provided
+// WithServer initializes HTTP server during Build if configuration is provided
 func (b *AppBuilder) WithServer() *AppBuilder {
-	if b.server != nil {
-		return b
-	}
-	initOptionalComponent(b, &b.server, func(o OptionalConfigProvider) *platformserver.Config { return o.ServerConfig() }, func(cfg platformserver.Config) (*platformserver.Server, error) {
+	addOptionalComponent(b, &b.server, func(o ServerConfigProvider) *platformserver.Config { return o.ServerConfig() }, func(_ context.Context, cfg platformserver.Config) (*platformserver.Server, error) {
 		return platformserver.New(cfg)
 	}, "server", "HTTP server initialized")
 	return b
 }
 
-// WithDatabase initializes database if configuration is provided
+// WithDatabase initializes database during Build if configuration is provided
 func (b *AppBuilder) WithDatabase() *AppBuilder {
-	if b.database != nil {
-		return b
-	}
-	initOptionalComponent(b, &b.database, func(o OptionalConfigProvider) *platformdatabase.Config { return o.DatabaseConfig() }, func(cfg platformdatabase.Config) (*platformdatabase.Database, error) {
-		return platformdatabase.New(cfg)
+	addOptionalComponent(b, &b.database, func(o DatabaseConfigProvider) *platformdatabase.Config { return o.DatabaseConfig() }, func(ctx context.Context, cfg platformdatabase.Config) (*platformdatabase.Database, error) {
+		return platformdatabase.NewContext(ctx, cfg)
 	}, "database", "Database initialized")
 	return b
 }
 
-// WithCache initializes cache if configuration is provided
+// WithCache initializes cache during Build if configuration is provided
 func (b *AppBuilder) WithCache() *AppBuilder {
-	if b.cache != nil {
-		return b
-	}
-	initOptionalComponent(b, &b.cache, func(o OptionalConfigProvider) *platformcache.Config { return o.CacheConfig() }, func(cfg platformcache.Config) (platformcache.Cache, error) {
-		return platformcache.New(cfg)
+	addOptionalComponent(b, &b.cache, func(o CacheConfigProvider) *platformcache.Config { return o.CacheConfig() }, func(ctx context.Context, cfg platformcache.Config) (platformcache.Cache, error) {
+		return platformcache.NewContext(ctx, cfg)
 	}, "cache", "Cache initialized")
 	return b
 }
 
-// WithKafka initializes Kafka producer and event publisher if configuration is provided
+// WithKafka initializes Kafka producer and event publisher during Build if configuration is provided
 func (b *AppBuilder) WithKafka() *AppBuilder {
-	if b.eventPublisher != nil {
-		return b
-	}
-	initOptionalComponent(b, &b.eventPublisher, func(o OptionalConfigProvider) *kafka.Config { return o.KafkaConfig() }, func(cfg kafka.Config) (*kafka.KafkaEventPublisher, error) {
-		producer, err := kafka.NewProducer(cfg)
+	addOptionalComponent(b, &b.eventPublisher, func(o KafkaConfigProvider) *kafka.Config { return o.KafkaConfig() }, func(ctx context.Context, cfg kafka.Config) (*kafka.KafkaEventPublisher, error) {
+		producer, err := kafka.NewProducerContext(ctx, cfg)
 		if err != nil {
 			return nil, err
 		}
-		return kafka.NewKafkaEventPublisher(producer, cfg.Producer.Topic), nil
+		// Kafka metrics are served from the shared metrics endpoint registry
+		if cfg.Reliability.EnableMetrics && b.metrics != nil {
+			producer.SetMetrics(kafka.NewKafkaMetrics(b.metrics.Registry(), ""))
+		}
+		publisher := kafka.NewKafkaEventPublisher(producer, cfg.Producer.Topic)
+		publisher.SetTracing(cfg.Tracing)
+		return publisher, nil
 	}, "kafka producer", "Kafka producer initialized")
 	return b
 }
 
-// WithGRPC initializes gRPC server if configuration is provided
+// WithGRPC initializes gRPC server during Build if configuration is provided
 func (b *AppBuilder) WithGRPC() *AppBuilder {
-	if b.grpcServer != nil {
-		return b
-	}
-	initOptionalComponent(b, &b.grpcServer, func(o OptionalConfigProvider) *platformgrpc.Config { return o.GRPCConfig() }, func(cfg platformgrpc.Config) (*platformgrpc.Server, error) {
+	addOptionalComponent(b, &b.grpcServer, func(o GRPCConfigProvider) *platformgrpc.Config { return o.GRPCConfig() }, func(_ context.Context, cfg platformgrpc.Config) (*platformgrpc.Server, error) {
+		// Serve gRPC metrics from the shared metrics endpoint registry
+		if cfg.MetricsRegisterer == nil && b.metrics != nil {
+			cfg.MetricsRegisterer = b.metrics.Registry()
+		}
 		return platformgrpc.NewServer(cfg, b.logger, nil)
 	}, "grpc server", "gRPC server initialized")
 	return b
@@ -209,18 +345,58 @@ func (b *AppBuilder) WithAll() *AppBuilder {
 
 // Build creates the App instance and returns any errors that occurred during initialization
 func (b *AppBuilder) Build() (*App, error) {
+	return b.BuildContext(context.Background())
+}
+
+// BuildContext initializes the requested components and creates the App
+// instance. ctx bounds component initialization: database and cache
+// connections and the Kafka broker check are aborted once ctx is done, and the
+// returned error names the component that was being initialized.
+func (b *AppBuilder) BuildContext(ctx context.Context) (*App, error) {
 	// Logger is required
 	if b.logger == nil {
 		b.WithLogger()
 	}
 
+	// Steps are skipped when the logger failed; name the components that
+	// cannot work without it
+	if b.loggerFailed && b.requested["grpc server"] {
+		if provider, ok := b.config.(GRPCConfigProvider); ok && provider.GRPCConfig() != nil {
+			b.errors = append(b.errors, fmt.Errorf("init grpc server: %w", ErrLoggerRequired))
+		}
+	}
+
+	if len(b.errors) == 0 {
+		for _, step := range b.steps {
+			if err := ctx.Err(); err != nil {
+				b.errors = append(b.errors, fmt.Errorf("init %s: %w", step.name, err))
+				break
+			}
+			if err := step.run(ctx); err != nil {
+				b.errors = append(b.errors, fmt.Errorf("init %s: %w", step.name, err))
+			}
+		}
+		b.steps = nil
+	}
+
 	if len(b.errors) > 0 {
-		return nil, fmt.Errorf("failed to build app: %w", errors.Join(b.errors...))
+		err := fmt.Errorf("failed to build app: %w", errors.Join(b.errors...))
+		// Release components that were initialized before the failure
+		if closeErr := b.app().Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("close partially initialized app: %w", closeErr))
+		}
+		return nil, err
 	}
 
 	platformlogger.Info().Msg("All requested application components initialized successfully")
 
+	return b.app(), nil
+}
+
+// app assembles an App from the components initialized so far.
+func (b *AppBuilder) app() *App {
 	return &App{
+		Info:           b.info,
 		Config:         b.config,
 		Logger:         b.logger,
 		Metrics:        b.metrics,
@@ -230,7 +406,7 @@ func (b *AppBuilder) Build() (*App, error) {
 		Database:       b.database,
 		Cache:          b.cache,
 		EventPublisher: b.eventPublisher,
-	}, nil
+	}
 }
 
 // New initializes all common infrastructure services based on the provided configuration
@@ -244,69 +420,275 @@ func NewWithLogger(cfg ConfigProvider) (*App, error) {
 	return NewBuilder(cfg).WithLogger().Build()
 }
 
-// Close stops metrics, health checks and closes database connections.
-func (a *App) Close() error {
-	if a == nil {
+// BootstrapWithConfig loads configuration from configPath into cfg, which must
+// be a pointer, and initializes all components.
+func BootstrapWithConfig(cfg ConfigProvider, configPath string) (*App, error) {
+	if err := loadConfig(cfg, configPath); err != nil {
+		return nil, err
+	}
+	return New(cfg)
+}
+
+// BootstrapWithConfigAndLogger loads configuration from configPath into cfg,
+// which must be a pointer, and initializes only the logger.
+func BootstrapWithConfigAndLogger(cfg ConfigProvider, configPath string) (*App, error) {
+	if err := loadConfig(cfg, configPath); err != nil {
+		return nil, err
+	}
+	return NewWithLogger(cfg)
+}
+
+// BootstrapWithGlobalConfig loads configuration from configPath into cfg,
+// which must be a pointer, initializes the global logger tagged with the
+// service name and version and builds all components.
+func BootstrapWithGlobalConfig(cfg ConfigProvider, configPath, name, version string) (*App, error) {
+	if err := loadConfig(cfg, configPath); err != nil {
+		return nil, err
+	}
+	return NewBuilder(cfg).
+		WithApplicationInfo(ApplicationInfo{Name: name, Version: version}).
+		WithAll().
+		Build()
+}
+
+// loadConfig reads configPath into cfg. Missing files are reported as
+// config.ErrConfigNotFound, validation failures as config.ErrConfigValidation.
+func loadConfig(cfg ConfigProvider, configPath string) error {
+	err := platformconfig.Load(cfg, configPath)
+	switch {
+	case err == nil:
 		return nil
+	case errors.Is(err, fs.ErrNotExist) && !errors.Is(err, platformconfig.ErrConfigNotFound):
+		return fmt.Errorf("load config %s: %w: %w", configPath, platformconfig.ErrConfigNotFound, err)
+	default:
+		return fmt.Errorf("load config %s: %w", configPath, err)
 	}
+}
 
-	platformlogger.Info().Msg("Shutting down application components")
+// Start launches the HTTP and gRPC servers and watches the metrics and
+// healthcheck servers, which serve from initialization on. It blocks until
+// ctx is cancelled or a component fails, then stops the HTTP and gRPC servers
+// and returns the first fatal error. Other components are released by Close.
+func (a *App) Start(ctx context.Context) error {
+	g, gctx := errgroup.WithContext(ctx)
 
+	if a.Server != nil {
+		g.Go(func() error {
+			platformlogger.Info().Msg("Starting HTTP server")
+			if err := a.Server.Start(); err != nil {
+				return fmt.Errorf("http server: %w", err)
+			}
+			return nil
+		})
+	}
+
+	if a.GRPCServer != nil {
+		g.Go(func() error {
+			platformlogger.Info().Msg("Starting gRPC server")
+			if err := a.GRPCServer.Start(); err != nil {
+				return fmt.Errorf("grpc server: %w", err)
+			}
+			return nil
+		})
+	}
+
+	if a.Metrics != nil {
+		g.Go(func() error { return watchErrors(gctx, "metrics", a.Metrics.Errors()) })
+	}
+
+	if a.Healthcheck != nil {
+		g.Go(func() error { return watchErrors(gctx, "healthcheck", a.Healthcheck.Errors()) })
+	}
+
+	g.Go(func() error {
+		<-gctx.Done()
+		return a.stopServers()
+	})
+
+	return g.Wait()
+}
+
+// stopServers stops the HTTP and gRPC servers so that Start can return.
+// Close then skips them.
+func (a *App) stopServers() error {
+	if !a.serversStopped.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	var errs []error
 	if a.Server != nil {
 		if err := a.Server.Stop(); err != nil {
-			platformlogger.Error().Err(err).Msg("Failed to stop HTTP server")
-			return err
+			errs = append(errs, fmt.Errorf("stop http server: %w", err))
 		}
-		platformlogger.Info().Msg("HTTP server stopped")
 	}
-
 	if a.GRPCServer != nil {
-		if err := a.GRPCServer.Stop(context.Background()); err != nil {
-			platformlogger.Error().Err(err).Msg("Failed to stop gRPC server")
-			return err
+		if err := a.stopGRPCServer(); err != nil {
+			errs = append(errs, fmt.Errorf("stop grpc server: %w", err))
 		}
-		platformlogger.Info().Msg("gRPC server stopped")
 	}
+	return errors.Join(errs...)
+}
 
-	if a.Database != nil {
-		a.Database.Close()
-		platformlogger.Info().Msg("Database connection closed")
+// stopGRPCServer gracefully stops the gRPC server, forcing it to stop once
+// ShutdownTimeout elapses.
+func (a *App) stopGRPCServer() error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout())
+	defer cancel()
+	return a.GRPCServer.Stop(ctx)
+}
+
+// shutdownTimeout returns ShutdownTimeout or DefaultShutdownTimeout if unset.
+func (a *App) shutdownTimeout() time.Duration {
+	if a.ShutdownTimeout > 0 {
+		return a.ShutdownTimeout
 	}
+	return DefaultShutdownTimeout
+}
 
-	if a.Metrics != nil {
-		if err := a.Metrics.Stop(); err != nil {
-			platformlogger.Error().Err(err).Msg("Failed to stop metrics")
-			return err
+// watchErrors returns the first error received from errs, or nil once ctx is
+// done or errs is closed.
+func watchErrors(ctx context.Context, name string, errs <-chan error) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case err, ok := <-errs:
+		if !ok {
+			return nil
 		}
-		platformlogger.Info().Msg("Metrics stopped")
+		return fmt.Errorf("%s server: %w", name, err)
 	}
+}
 
-	if a.Cache != nil {
-		if err := a.Cache.Close(); err != nil {
-			platformlogger.Error().Err(err).Msg("Failed to close cache")
-			return err
-		}
-		platformlogger.Info().Msg("Cache closed")
+// Run starts the application and blocks until ctx is cancelled,
+// SIGINT/SIGTERM is received or a component fails. It then shuts the
+// application down within ShutdownTimeout and returns the aggregated errors.
+func (a *App) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	startErr := a.Start(ctx)
+	if startErr != nil {
+		platformlogger.Error().Err(startErr).Msg("Application component failed")
 	}
+	platformlogger.Info().Msg("Shutdown requested")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout())
+	defer cancel()
+
+	return errors.Join(startErr, a.Shutdown(shutdownCtx))
+}
+
+// Shutdown runs Close and waits for it until ctx is done. Components keep
+// their dependency stop order; if the deadline passes first an error
+// wrapping ctx.Err() is returned while Close continues in the background.
+func (a *App) Shutdown(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Close()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		platformlogger.Error().Err(ctx.Err()).Msg("Application shutdown did not complete in time")
+		return fmt.Errorf("shutdown: %w", ctx.Err())
+	}
+}
+
+// closeStep stops a single component during Close.
+type closeStep struct {
+	name  string
+	close func() error
+}
 
+// Close releases application components in dependency order. Servers are
+// stopped first, unless Start already stopped them, so that no request uses
+// the resources being closed, then database, cache and event publisher, and
+// finally metrics and healthcheck so that the shutdown stays observable.
+// Components within a tier are closed concurrently. All components are closed
+// even if some fail; their errors are joined.
+func (a *App) Close() error {
+	if a == nil {
+		return nil
+	}
+
+	platformlogger.Info().Msg("Shutting down application components")
+
+	var servers, resources, observability []closeStep
+
+	stopServers := a.serversStopped.CompareAndSwap(false, true)
+	if stopServers && a.Server != nil {
+		servers = append(servers, closeStep{"HTTP server", a.Server.Stop})
+	}
+	if stopServers && a.GRPCServer != nil {
+		servers = append(servers, closeStep{"gRPC server", a.stopGRPCServer})
+	}
+
+	if a.Database != nil {
+		resources = append(resources, closeStep{"database", func() error {
+			a.Database.Close()
+			return nil
+		}})
+	}
+	if a.Cache != nil {
+		resources = append(resources, closeStep{"cache", a.Cache.Close})
+	}
 	if a.EventPublisher != nil {
-		if err := a.EventPublisher.Close(); err != nil {
-			platformlogger.Error().Err(err).Msg("Failed to close event publisher")
-			return err
-		}
-		platformlogger.Info().Msg("Event publisher closed")
+		resources = append(resources, closeStep{"event publisher", a.EventPublisher.Close})
 	}
 
+	if a.Metrics != nil {
+		observability = append(observability, closeStep{"metrics", a.Metrics.Stop})
+	}
 	if a.Healthcheck != nil {
-		if err := a.Healthcheck.Stop(); err != nil {
-			platformlogger.Error().Err(err).Msg("Failed to stop healthcheck")
-			return err
-		}
-		platformlogger.Info().Msg("Healthcheck stopped")
+		observability = append(observability, closeStep{"healthcheck", func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), a.Healthcheck.ShutdownTimeout())
+			defer cancel()
+			return a.Healthcheck.Shutdown(ctx)
+		}})
+	}
+
+	var errs []error
+	for _, tier := range [][]closeStep{servers, resources, observability} {
+		errs = append(errs, closeTier(tier)...)
+	}
+
+	if len(errs) == 0 {
+		platformlogger.Info().Msg("Application shutdown completed")
 	}
 
-	platformlogger.Info().Msg("Application shutdown completed")
-	return nil
+	// Flush the logger last so shutdown records are not lost
+	if err := platformlogger.Sync(); err != nil {
+		errs = append(errs, fmt.Errorf("sync logger: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// closeTier runs steps concurrently and returns their errors.
+func closeTier(steps []closeStep) []error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, step := range steps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := step.close(); err != nil {
+				platformlogger.Error().Err(err).Msgf("Failed to stop %s", step.name)
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("stop %s: %w", step.name, err))
+				mu.Unlock()
+				return
+			}
+			platformlogger.Info().Msgf("%s stopped", step.name)
+		}()
+	}
+	wg.Wait()
+	return errs
 }
 
 // getEnvironment определяет окружение приложения
@@ -2,13 +2,22 @@ package app
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	monkey "bou.ke/monkey"
+	platformcache "gitlab.com/zynero/shared/cache"
+	platformconfig "gitlab.com/zynero/shared/config"
+	platformdatabase "gitlab.com/zynero/shared/database"
 	platformgrpc "gitlab.com/zynero/shared/grpc"
+	platformhealthcheck "gitlab.com/zynero/shared/healthcheck"
 	platformlogger "gitlab.com/zynero/shared/logger"
+	platformmetrics "gitlab.com/zynero/shared/metrics"
 	platformserver "gitlab.com/zynero/shared/server"
 	"gitlab.com/zynero/shared/transport/kafka"
 )
@@ -34,40 +43,63 @@ type TestOptionalConfig struct {
 }
 
 // MetricsConfig возвращает nil (компонент не нужен)
-func (c TestOptionalConfig) MetricsConfig() *platformlogger.Config {
+func (c TestOptionalConfig) MetricsConfig() *platformmetrics.Config {
 	return nil
 }
 
 // HealthcheckConfig возвращает nil (компонент не нужен)
-func (c TestOptionalConfig) HealthcheckConfig() *platformlogger.Config {
+func (c TestOptionalConfig) HealthcheckConfig() *platformhealthcheck.Config {
 	return nil
 }
 
 // ServerConfig возвращает nil (компонент не нужен)
-func (c TestOptionalConfig) ServerConfig() *platformlogger.Config {
+func (c TestOptionalConfig) ServerConfig() *platformserver.Config {
 	return nil
 }
 
 // DatabaseConfig возвращает nil (компонент не нужен)
-func (c TestOptionalConfig) DatabaseConfig() *platformlogger.Config {
+func (c TestOptionalConfig) DatabaseConfig() *platformdatabase.Config {
 	return nil
 }
 
 // CacheConfig возвращает nil (компонент не нужен)
-func (c TestOptionalConfig) CacheConfig() *platformlogger.Config {
+func (c TestOptionalConfig) CacheConfig() *platformcache.Config {
 	return nil
 }
 
 // KafkaConfig возвращает nil (компонент не нужен)
-func (c TestOptionalConfig) KafkaConfig() *platformlogger.Config {
+func (c TestOptionalConfig) KafkaConfig() *kafka.Config {
 	return nil
 }
 
 // GRPCConfig возвращает nil (компонент не нужен)
-func (c TestOptionalConfig) GRPCConfig() *platformlogger.Config {
+func (c TestOptionalConfig) GRPCConfig() *platformgrpc.Config {
 	return nil
 }
 
+var _ OptionalConfigProvider = TestOptionalConfig{}
+
+// TestServerConfig представляет конфигурацию, реализующую только ServerConfigProvider
+type TestServerConfig struct {
+	TestConfig
+	Server platformserver.Config
+}
+
+// ServerConfig возвращает конфигурацию HTTP сервера
+func (c TestServerConfig) ServerConfig() *platformserver.Config {
+	return &c.Server
+}
+
+// TestMismatchedConfig объявляет опциональный метод с неверной сигнатурой
+type TestMismatchedConfig struct {
+	TestConfig
+}
+
+// MetricsConfig возвращает конфигурацию неверного типа
+func (c TestMismatchedConfig) MetricsConfig() *platformlogger.Config {
+	return &platformlogger.Config{}
+}
+
 type fakeCache struct{ closed bool }
 
 func (f *fakeCache) Get(ctx context.Context, key string) ([]byte, error) { return nil, nil }
@@ -258,3 +290,322 @@ func TestAppClose(t *testing.T) {
 		t.Errorf("Closing nil app should not return error: %v", err)
 	}
 }
+
+func TestAppStartReturnsOnCancel(t *testing.T) {
+	cfg := TestConfig{
+		Logger: platformlogger.Config{
+			Level:  "info",
+			Format: "console",
+			Output: "stdout",
+		},
+	}
+
+	application, err := NewWithLogger(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+	defer application.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := application.Start(ctx); err != nil {
+		t.Errorf("Start with cancelled context should return nil, got: %v", err)
+	}
+}
+
+func TestAppCloseAfterStartStopsServersOnce(t *testing.T) {
+	cfg := TestConfig{
+		Logger: platformlogger.Config{Level: "info", Format: "console", Output: "stdout"},
+	}
+
+	application, err := NewWithLogger(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+	server, err := platformserver.New(platformserver.Config{ShutdownTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	hooks := 0
+	server.RegisterOnShutdown(func() { hooks++ })
+	application.Server = server
+
+	// Start останавливает серверы при отмене контекста, Run затем вызывает Close
+	if err := application.stopServers(); err != nil {
+		t.Fatalf("stopServers() error = %v", err)
+	}
+	if err := application.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if hooks != 1 {
+		t.Errorf("shutdown hooks ran %d times, want 1", hooks)
+	}
+}
+
+func TestBuildReturnsLoggerError(t *testing.T) {
+	cfg := TestConfig{
+		Logger: platformlogger.Config{
+			Output: "/nonexistent-dir/app.log",
+		},
+	}
+
+	application, err := NewBuilder(cfg).WithLogger().WithLogger().Build()
+	if err == nil {
+		t.Fatal("Build should fail when the logger cannot be initialized")
+	}
+	if application != nil {
+		t.Error("Build should not return an app on error")
+	}
+	if n := strings.Count(err.Error(), "init logger"); n != 1 {
+		t.Errorf("logger error reported %d times, want 1: %v", n, err)
+	}
+}
+
+// TestGRPCConfig представляет конфигурацию, реализующую только GRPCConfigProvider
+type TestGRPCConfig struct {
+	TestConfig
+	GRPC platformgrpc.Config
+}
+
+// GRPCConfig возвращает конфигурацию gRPC сервера
+func (c TestGRPCConfig) GRPCConfig() *platformgrpc.Config {
+	return &c.GRPC
+}
+
+func TestBuildReportsLoggerRequiredForGRPC(t *testing.T) {
+	cfg := TestGRPCConfig{
+		TestConfig: TestConfig{Logger: platformlogger.Config{Output: "/nonexistent-dir/app.log"}},
+	}
+
+	_, err := NewBuilder(cfg).WithGRPC().Build()
+	if !errors.Is(err, ErrLoggerRequired) {
+		t.Fatalf("Build() error = %v, want ErrLoggerRequired", err)
+	}
+	if !strings.Contains(err.Error(), "init logger") || !strings.Contains(err.Error(), "init grpc server") {
+		t.Errorf("error should name the logger and the gRPC server: %v", err)
+	}
+
+	// Без конфигурации gRPC сообщается только ошибка логгера
+	if _, err := NewBuilder(cfg.TestConfig).WithGRPC().Build(); errors.Is(err, ErrLoggerRequired) {
+		t.Errorf("Build() without gRPC config error = %v, want only the logger error", err)
+	}
+}
+
+func TestAppBuilderInitializesConfiguredComponent(t *testing.T) {
+	cfg := TestServerConfig{
+		TestConfig: TestConfig{
+			Logger: platformlogger.Config{Level: "info", Format: "console", Output: "stdout"},
+		},
+		Server: platformserver.Config{Address: "127.0.0.1:0"},
+	}
+
+	application, err := NewBuilder(cfg).WithAll().Build()
+	if err != nil {
+		t.Fatalf("Failed to build app: %v", err)
+	}
+
+	if application.Server == nil {
+		t.Error("Server should be initialized when ServerConfig returns a config")
+	}
+	if application.Metrics != nil || application.Database != nil || application.GRPCServer != nil {
+		t.Error("Components without a config provider should not be initialized")
+	}
+}
+
+func TestAppBuilderRejectsMismatchedOptionalMethod(t *testing.T) {
+	cfg := TestMismatchedConfig{
+		TestConfig: TestConfig{
+			Logger: platformlogger.Config{Level: "info", Format: "console", Output: "stdout"},
+		},
+	}
+
+	_, err := NewBuilder(cfg).WithLogger().WithMetrics().Build()
+	if err == nil {
+		t.Fatal("Build should fail when an optional config method has the wrong signature")
+	}
+	if !strings.Contains(err.Error(), "MetricsConfig") {
+		t.Errorf("error should name the mismatched method: %v", err)
+	}
+}
+
+func TestBuildContextCancelled(t *testing.T) {
+	cfg := TestServerConfig{
+		TestConfig: TestConfig{
+			Logger: platformlogger.Config{Level: "info", Format: "console", Output: "stdout"},
+		},
+		Server: platformserver.Config{Address: "127.0.0.1:0"},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewBuilder(cfg).WithServer().BuildContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "init server") {
+		t.Errorf("error should name the component: %v", err)
+	}
+}
+
+func TestBootstrapWithGlobalConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "logger:\n  level: info\n  format: json\n  output: stdout\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg := &TestConfig{}
+	application, err := BootstrapWithGlobalConfig(cfg, path, "user-service", "1.0.0")
+	if err != nil {
+		t.Fatalf("Failed to bootstrap app: %v", err)
+	}
+	defer application.Close()
+
+	if cfg.Logger.Level != "info" {
+		t.Errorf("config was not loaded, got level %q", cfg.Logger.Level)
+	}
+	if application.Info.Name != "user-service" || application.Info.Version != "1.0.0" {
+		t.Errorf("unexpected application info: %+v", application.Info)
+	}
+}
+
+func TestBootstrapWithConfigMissingFile(t *testing.T) {
+	_, err := BootstrapWithConfig(&TestConfig{}, filepath.Join(t.TempDir(), "missing.yaml"))
+	if !errors.Is(err, platformconfig.ErrConfigNotFound) {
+		t.Fatalf("expected ErrConfigNotFound, got %v", err)
+	}
+}
+
+func TestAppCloseContinuesAfterError(t *testing.T) {
+	cfg := TestConfig{
+		Logger: platformlogger.Config{Level: "info", Format: "console", Output: "stdout"},
+	}
+
+	application, err := NewWithLogger(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+
+	fc := &fakeCache{}
+	fp := &fakeProducer{}
+	application.Cache = fc
+	application.EventPublisher = kafka.NewKafkaEventPublisher(fp, "test")
+	application.Server = &platformserver.Server{}
+
+	stopErr := errors.New("drain failed")
+	patchHTTP := monkey.PatchInstanceMethod(reflect.TypeOf(&platformserver.Server{}), "Stop", func(*platformserver.Server) error {
+		return stopErr
+	})
+	defer patchHTTP.Unpatch()
+
+	err = application.Close()
+	if !errors.Is(err, stopErr) {
+		t.Fatalf("expected HTTP stop error, got %v", err)
+	}
+	if !fc.closed || !fp.closed {
+		t.Error("components after the failing one should still be closed")
+	}
+}
+
+func TestAppReload(t *testing.T) {
+	application, err := NewWithLogger(TestConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+	defer application.Close()
+
+	var got []ConfigProvider
+	hookErr := errors.New("hook failed")
+	application.OnReload(func(cfg ConfigProvider) error {
+		got = append(got, cfg)
+		return hookErr
+	})
+	application.OnReload(func(cfg ConfigProvider) error {
+		got = append(got, cfg)
+		return nil
+	})
+
+	newCfg := TestConfig{Logger: platformlogger.Config{Level: "debug"}}
+	err = application.Reload(newCfg)
+	if !errors.Is(err, hookErr) {
+		t.Errorf("expected hook error, got %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("expected both hooks to run, got %d", len(got))
+	}
+	if !reflect.DeepEqual(application.CurrentConfig(), newCfg) {
+		t.Error("CurrentConfig() should return the reloaded config")
+	}
+	if !reflect.DeepEqual(application.Config, TestConfig{}) {
+		t.Error("App.Config should keep the config the app was built with")
+	}
+
+	if err := application.Reload(TestConfig{Logger: platformlogger.Config{Level: "verbose"}}); err == nil {
+		t.Error("expected error for invalid log level")
+	}
+	_ = platformlogger.SetLevel("info")
+}
+
+func TestAppWatchConfig(t *testing.T) {
+	application, err := NewWithLogger(TestConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+	defer application.Close()
+	defer platformlogger.SetLevel("info")
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("logger:\n  level: info\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := application.WatchConfig(ctx, configPath, func() ConfigProvider { return &TestConfig{} }); err != nil {
+		t.Fatalf("WatchConfig() error = %v", err)
+	}
+
+	level := func() string {
+		if cfg, ok := application.CurrentConfig().(*TestConfig); ok {
+			return cfg.Logger.Level
+		}
+		return ""
+	}
+	waitLevel := func(want string) bool {
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if level() == want {
+				return true
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		return false
+	}
+
+	if err := os.WriteFile(configPath, []byte("logger:\n  level: debug\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if !waitLevel("debug") {
+		t.Fatalf("CurrentConfig() level = %q, want debug after file change", level())
+	}
+
+	// После отмены ctx изменения файла не применяются
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(configPath, []byte("logger:\n  level: warn\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if waitLevel("warn") {
+		t.Error("config reloaded after ctx was cancelled")
+	}
+}
+
+func TestAppWatchConfigMissingDir(t *testing.T) {
+	application := &App{}
+	err := application.WatchConfig(context.Background(), "/nonexistent-dir/config.yaml", func() ConfigProvider { return &TestConfig{} })
+	if err == nil {
+		t.Error("WatchConfig() should fail when the config dir does not exist")
+	}
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	platformconfig "gitlab.com/zynero/shared/config"
+	platformlogger "gitlab.com/zynero/shared/logger"
+)
+
+// OnReload registers fn to be called by Reload with the new configuration.
+// Hooks run in registration order; use them to re-apply settings of service
+// specific components.
+func (a *App) OnReload(fn func(cfg ConfigProvider) error) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+	a.reloadHooks = append(a.reloadHooks, fn)
+}
+
+// CurrentConfig returns the configuration applied by the last Reload, or
+// Config if the application has not been reloaded. It is safe to call
+// concurrently with Reload.
+func (a *App) CurrentConfig() ConfigProvider {
+	if cfg := a.reloaded.Load(); cfg != nil {
+		return *cfg
+	}
+	return a.Config
+}
+
+// Reload applies cfg to the running application and makes it the
+// CurrentConfig.
+// Only the log level is re-applied by the platform itself. Metrics,
+// healthcheck, HTTP and gRPC servers, database, cache and Kafka keep the
+// settings they were built with: changing them requires a restart. Errors of
+// the hooks registered with OnReload are joined; a failing hook does not stop
+// the others.
+func (a *App) Reload(cfg ConfigProvider) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	var errs []error
+	if level := cfg.LoggerConfig().Level; level != "" {
+		if err := platformlogger.SetLevel(level); err != nil {
+			errs = append(errs, fmt.Errorf("reload logger: %w", err))
+		} else {
+			platformlogger.Info().Str("level", level).Msg("Log level reloaded")
+		}
+	}
+
+	for _, hook := range a.reloadHooks {
+		if err := hook(cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	a.reloaded.Store(&cfg)
+	return errors.Join(errs...)
+}
+
+// WatchConfig watches configPath until ctx is cancelled and calls Reload when
+// the file changes. newConfig must return a pointer to a fresh configuration
+// value to load the file into. Invalid configurations are logged and not
+// applied. It returns an error if the watcher cannot be started.
+func (a *App) WatchConfig(ctx context.Context, configPath string, newConfig func() ConfigProvider) error {
+	loader := platformconfig.NewLoader(configPath)
+	loader.OnConfigChange(func() {
+		cfg := newConfig()
+		if err := loader.Load(cfg); err != nil {
+			platformlogger.Error().Err(err).Msg("Failed to reload config")
+			return
+		}
+		if err := a.Reload(cfg); err != nil {
+			platformlogger.Error().Err(err).Msg("Failed to apply reloaded config")
+		}
+	})
+	return loader.WatchConfigContext(ctx)
+}
@@ -38,10 +38,16 @@ type Cache interface {
 
 // New создает новый экземпляр кеша на основе конфигурации
 func New(config Config) (Cache, error) {
+	return NewContext(context.Background(), config)
+}
+
+// NewContext создает кеш на основе конфигурации. Проверка подключения к Redis
+// прерывается при отмене ctx.
+func NewContext(ctx context.Context, config Config) (Cache, error) {
 	if !config.Enabled {
 		return newNoopCache(), nil
 	}
-	return newRedisCache(config)
+	return newRedisCache(ctx, config)
 }
 
 // redisCache реализует Cache с использованием Redis
@@ -50,14 +56,15 @@ type redisCache struct {
 	cfg    Config
 }
 
-func newRedisCache(config Config) (*redisCache, error) {
+func newRedisCache(ctx context.Context, config Config) (*redisCache, error) {
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", config.Host, config.Port),
 		Password: config.Password,
 		DB:       config.DB,
 	})
 
-	if err := rdb.Ping(context.Background()).Err(); err != nil {
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		_ = rdb.Close()
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
@@ -95,6 +102,27 @@ func (rc *redisCache) Set(ctx context.Context, key string, value any, ttl time.D
 	return nil
 }
 
+// SetNX сохраняет значение, только если ключ отсутствует, и сообщает, было ли
+// значение записано. Метод не входит в Cache: кеш, созданный New, реализует
+// transport.DedupStore и приводится к нему проверкой типа.
+func (rc *redisCache) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	data, err := rc.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal value for key %s: %w", key, err)
+	}
+
+	actualTTL := rc.cfg.TTL
+	if ttl > 0 {
+		actualTTL = ttl
+	}
+
+	ok, err := rc.client.SetNX(ctx, key, data, actualTTL).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to setnx key %s in redis: %w", key, err)
+	}
+	return ok, nil
+}
+
 func (rc *redisCache) Delete(ctx context.Context, key string) error {
 	if err := rc.client.Del(ctx, key).Err(); err != nil {
 		return fmt.Errorf("failed to delete key %s from redis: %w", key, err)
@@ -129,6 +157,11 @@ func (nc *noopCache) Set(_ context.Context, _ string, _ any, _ time.Duration) er
 	return nil
 }
 
+// SetNX всегда сообщает о записи: без кеша ключи не считаются существующими
+func (nc *noopCache) SetNX(_ context.Context, _ string, _ any, _ time.Duration) (bool, error) {
+	return true, nil
+}
+
 func (nc *noopCache) Delete(_ context.Context, _ string) error {
 	return nil
 }
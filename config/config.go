@@ -1,13 +1,17 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 )
 
@@ -17,6 +21,8 @@ var (
 	ErrConfigInvalid    = errors.New("invalid config")
 	ErrConfigValidation = errors.New("config validation failed")
 	ErrConfigUnmarshal  = errors.New("failed to unmarshal config")
+	ErrRemoteConfig     = errors.New("failed to read remote config")
+	ErrConfigKeyMissing = errors.New("config key not found")
 )
 
 const (
@@ -34,6 +40,14 @@ type Configurable interface {
 // Loader предоставляет функциональность для загрузки конфигурации
 type Loader struct {
 	viper *viper.Viper
+
+	// remote включается в NewRemoteLoader: конфигурация читается из
+	// Consul/etcd вместо файла
+	remote   bool
+	onChange func()
+
+	// lenient отключает проверку неизвестных ключей при unmarshal
+	lenient bool
 }
 
 // getEnv возвращает текущее окружение
@@ -68,17 +82,27 @@ func NewLoader(configPath string) *Loader {
 	}
 }
 
-// Load загружает конфигурацию из файла в переданную структуру
+// Load загружает конфигурацию из файла (или удаленного хранилища для
+// NewRemoteLoader) в переданную структуру. Поля, отсутствующие в источнике,
+// получают значения из тегов default.
 func (l *Loader) Load(cfg Configurable) error {
-	// Чтение файла конфига
-	if err := l.viper.ReadInConfig(); err != nil {
+	if err := applyDefaults(l.viper, cfg); err != nil {
+		return fmt.Errorf("%w: %v", ErrConfigInvalid, err)
+	}
+
+	if l.remote {
+		if err := l.readRemoteConfig(); err != nil {
+			return err
+		}
+	} else if err := l.viper.ReadInConfig(); err != nil {
+		// Чтение файла конфига
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
 			return fmt.Errorf("%w: %v", ErrConfigNotFound, err)
 		}
 		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	if err := l.viper.UnmarshalExact(cfg); err != nil {
+	if err := l.unmarshal(l.viper, cfg); err != nil {
 		return fmt.Errorf("%w: %v", ErrConfigUnmarshal, err)
 	}
 
@@ -90,6 +114,62 @@ func (l *Loader) Load(cfg Configurable) error {
 	return nil
 }
 
+// UnmarshalKey загружает секцию key уже прочитанной конфигурации в target
+// с той же строгостью, что и Load (см. SetStrict).
+// Теги default применяются относительно секции. Если target реализует
+// Configurable, секция проверяется через Validate.
+func (l *Loader) UnmarshalKey(key string, target any) error {
+	if !l.viper.IsSet(key) {
+		return fmt.Errorf("%w: %s", ErrConfigKeyMissing, key)
+	}
+
+	sub := l.viper.Sub(key)
+	if sub == nil {
+		return fmt.Errorf("%w: key %s is not a section", ErrConfigUnmarshal, key)
+	}
+
+	if err := applyDefaults(sub, target); err != nil {
+		return fmt.Errorf("%w: %v", ErrConfigInvalid, err)
+	}
+
+	if err := l.unmarshal(sub, target); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrConfigUnmarshal, key, err)
+	}
+
+	if cfg, ok := target.(Configurable); ok {
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrConfigValidation, key, err)
+		}
+	}
+
+	return nil
+}
+
+// SetStrict включает или отключает строгий режим unmarshal. В строгом режиме
+// (по умолчанию) ключи конфигурации, которым нет соответствующего поля,
+// считаются ошибкой. Нестрогий режим нужен, когда файл общий для нескольких
+// сервисов и содержит чужие секции.
+func (l *Loader) SetStrict(strict bool) {
+	l.lenient = !strict
+}
+
+// unmarshal заполняет cfg из v. В строгом режиме ошибка перечисляет все
+// неизвестные ключи.
+func (l *Loader) unmarshal(v *viper.Viper, cfg any) error {
+	var md mapstructure.Metadata
+	if err := v.Unmarshal(cfg, func(dc *mapstructure.DecoderConfig) {
+		dc.Metadata = &md
+	}); err != nil {
+		return err
+	}
+
+	if l.lenient || len(md.Unused) == 0 {
+		return nil
+	}
+	sort.Strings(md.Unused)
+	return fmt.Errorf("unknown keys: %s", strings.Join(md.Unused, ", "))
+}
+
 // GetConfigPath возвращает путь к файлу конфигурации
 func (l *Loader) GetConfigPath() string {
 	return l.viper.ConfigFileUsed()
@@ -116,8 +196,64 @@ func (l *Loader) WatchConfig() {
 	l.viper.WatchConfig()
 }
 
+// WatchConfigContext наблюдает за файлом конфигурации до отмены ctx и вызывает
+// callback из OnConfigChange при его изменении. В отличие от WatchConfig
+// наблюдение останавливается вместе с ctx. Отслеживается директория файла,
+// поэтому замена файла переименованием и смена symlink (ConfigMap в
+// Kubernetes) тоже замечаются.
+func (l *Loader) WatchConfigContext(ctx context.Context) error {
+	if l.remote {
+		return fmt.Errorf("%w: use WatchRemoteConfig for a remote provider", ErrConfigInvalid)
+	}
+
+	file := filepath.Clean(l.viper.ConfigFileUsed())
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch config dir: %w", err)
+	}
+	realFile, _ := filepath.EvalSymlinks(file)
+
+	go func() {
+		defer watcher.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				current, _ := filepath.EvalSymlinks(file)
+				written := filepath.Clean(event.Name) == file &&
+					(event.Has(fsnotify.Write) || event.Has(fsnotify.Create))
+				relinked := current != "" && current != realFile
+				if !written && !relinked {
+					continue
+				}
+				realFile = current
+				if l.onChange != nil {
+					l.onChange()
+				}
+			case _, ok := <-watcher.Errors:
+				// Ошибки наблюдения не прерывают его
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
 // OnConfigChange устанавливает callback для обработки изменений конфигурации
 func (l *Loader) OnConfigChange(fn func()) {
+	l.onChange = fn
 	l.viper.OnConfigChange(func(e fsnotify.Event) {
 		fn()
 	})
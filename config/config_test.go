@@ -1,12 +1,17 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -240,6 +245,134 @@ debug: true
 	})
 }
 
+// DefaultsTestConfig структура с тегами default
+type DefaultsTestConfig struct {
+	Name    string        `mapstructure:"name"`
+	Port    int           `mapstructure:"port" default:"8080"`
+	Debug   bool          `mapstructure:"debug" default:"true"`
+	Timeout time.Duration `mapstructure:"timeout" default:"15s"`
+	Ratio   float64       `mapstructure:"ratio" default:"0.5"`
+	Hosts   []string      `mapstructure:"hosts" default:"a,b"`
+	Server  struct {
+		Host string `mapstructure:"host" default:"localhost"`
+	} `mapstructure:"server"`
+	Optional *struct {
+		Enabled bool `mapstructure:"enabled" default:"true"`
+	} `mapstructure:"optional"`
+}
+
+func (c *DefaultsTestConfig) Validate() error {
+	return nil
+}
+
+// InvalidDefaultsTestConfig структура с неверным значением по умолчанию
+type InvalidDefaultsTestConfig struct {
+	Timeout time.Duration `mapstructure:"timeout" default:"soon"`
+}
+
+func (c *InvalidDefaultsTestConfig) Validate() error {
+	return nil
+}
+
+func TestLoader_LoadDefaults(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "defaults.yaml")
+	err := os.WriteFile(configPath, []byte("name: \"test-app\"\nport: 9090\n"), 0644)
+	require.NoError(t, err)
+
+	cfg := &DefaultsTestConfig{}
+	require.NoError(t, NewLoader(configPath).Load(cfg))
+
+	assert.Equal(t, 9090, cfg.Port, "value from file overrides default")
+	assert.True(t, cfg.Debug)
+	assert.Equal(t, 15*time.Second, cfg.Timeout)
+	assert.Equal(t, 0.5, cfg.Ratio)
+	assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+	assert.Equal(t, "localhost", cfg.Server.Host)
+	assert.Nil(t, cfg.Optional, "pointer sections are not created by defaults")
+
+	err = NewLoader(configPath).Load(&InvalidDefaultsTestConfig{})
+	assert.ErrorIs(t, err, ErrConfigInvalid)
+}
+
+func TestLoader_UnmarshalKey(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "sections.yaml")
+	configContent := `
+kafka:
+  name: "events"
+  port: 9092
+invalid:
+  name: "events"
+  port: 9092
+  unknown: true
+scalar: 42
+`
+	err := os.WriteFile(configPath, []byte(configContent), 0644)
+	require.NoError(t, err)
+
+	loader := NewLoader(configPath)
+	require.NoError(t, loader.viper.ReadInConfig())
+
+	t.Run("section", func(t *testing.T) {
+		cfg := &InvalidTestConfig{}
+		err := loader.UnmarshalKey("kafka", cfg)
+		assert.ErrorIs(t, err, ErrConfigValidation, "section is validated")
+		assert.Equal(t, "events", cfg.Name)
+		assert.Equal(t, 9092, cfg.Port)
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		cfg := &DefaultsTestConfig{}
+		require.NoError(t, loader.UnmarshalKey("kafka", cfg))
+		assert.Equal(t, 9092, cfg.Port)
+		assert.Equal(t, 15*time.Second, cfg.Timeout)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		err := loader.UnmarshalKey("redis", &TestConfig{})
+		assert.ErrorIs(t, err, ErrConfigKeyMissing)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		err := loader.UnmarshalKey("invalid", &TestConfig{})
+		assert.ErrorIs(t, err, ErrConfigUnmarshal)
+	})
+
+	t.Run("not a section", func(t *testing.T) {
+		err := loader.UnmarshalKey("scalar", &TestConfig{})
+		assert.ErrorIs(t, err, ErrConfigUnmarshal)
+	})
+}
+
+func TestLoader_SetStrict(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "shared.yaml")
+	configContent := `
+name: "test-app"
+port: 8080
+billing_url: "http://billing"
+database:
+  host: "localhost"
+  pool: 10
+`
+	err := os.WriteFile(configPath, []byte(configContent), 0644)
+	require.NoError(t, err)
+
+	t.Run("strict by default", func(t *testing.T) {
+		err := NewLoader(configPath).Load(&TestConfig{})
+		require.ErrorIs(t, err, ErrConfigUnmarshal)
+		assert.Contains(t, err.Error(), "billing_url, database.pool")
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		loader := NewLoader(configPath)
+		loader.SetStrict(false)
+
+		cfg := &TestConfig{}
+		require.NoError(t, loader.Load(cfg))
+		assert.Equal(t, "test-app", cfg.Name)
+		assert.Equal(t, "localhost", cfg.Database.Host)
+	})
+}
+
 func TestLoader_GetConfigPath(t *testing.T) {
 	configPath := "test/config.yaml"
 	loader := NewLoader(configPath)
@@ -436,3 +569,139 @@ debug: true
 		Load(cfg, configPath)
 	}
 }
+
+// fakeRemote подменяет провайдеры viper/remote и отдает value
+type fakeRemote struct {
+	mu    sync.Mutex
+	value string
+}
+
+func (f *fakeRemote) set(value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.value = value
+}
+
+func (f *fakeRemote) Get(viper.RemoteProvider) (io.Reader, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return strings.NewReader(f.value), nil
+}
+
+func (f *fakeRemote) Watch(rp viper.RemoteProvider) (io.Reader, error) {
+	return f.Get(rp)
+}
+
+func (f *fakeRemote) WatchChannel(viper.RemoteProvider) (<-chan *viper.RemoteResponse, chan bool) {
+	return nil, nil
+}
+
+func withFakeRemote(t *testing.T, value string) *fakeRemote {
+	t.Helper()
+	prev := viper.RemoteConfig
+	t.Cleanup(func() { viper.RemoteConfig = prev })
+
+	remote := &fakeRemote{value: value}
+	viper.RemoteConfig = remote
+	return remote
+}
+
+func TestNewRemoteLoaderWithoutProviders(t *testing.T) {
+	prev := viper.RemoteConfig
+	viper.RemoteConfig = nil
+	defer func() { viper.RemoteConfig = prev }()
+
+	_, err := NewRemoteLoader(ProviderConsul, "localhost:8500", "config/app.yaml")
+	assert.ErrorIs(t, err, ErrRemoteConfig)
+}
+
+func TestNewRemoteLoader(t *testing.T) {
+	withFakeRemote(t, "")
+
+	_, err := NewRemoteLoader("zookeeper", "localhost:2181", "/config/app.yaml")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrConfigInvalid)
+
+	loader, err := NewRemoteLoader(ProviderConsul, "localhost:8500", "config/app.json")
+	require.NoError(t, err)
+	assert.True(t, loader.remote)
+	assert.Equal(t, "json", remoteConfigType("config/app.json"))
+	assert.Equal(t, "yaml", remoteConfigType("config/app"))
+}
+
+func TestLoader_LoadRemote(t *testing.T) {
+	remote := withFakeRemote(t, "name: remote-app\nport: 8080\n")
+
+	loader, err := NewRemoteLoader(ProviderConsul, "localhost:8500", "config/app.yaml")
+	require.NoError(t, err)
+
+	cfg := &TestConfig{}
+	require.NoError(t, loader.Load(cfg))
+	assert.Equal(t, "remote-app", cfg.Name)
+	assert.Equal(t, 8080, cfg.Port)
+
+	changed := make(chan struct{}, 1)
+	loader.OnConfigChange(func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, loader.WatchRemoteConfig(ctx, 10*time.Millisecond))
+
+	remote.set("name: remote-app\nport: 9090\n")
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("OnConfigChange callback not called after remote change")
+	}
+	assert.Equal(t, 9090, loader.GetInt("port"))
+}
+
+func TestLoader_WatchRemoteConfigRequiresRemote(t *testing.T) {
+	err := NewLoader("").WatchRemoteConfig(context.Background(), time.Second)
+	assert.ErrorIs(t, err, ErrConfigInvalid)
+}
+
+func TestLoader_WatchConfigContext(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("name: app\nport: 8080\n"), 0o644))
+
+	loader := NewLoader(configPath)
+	changed := make(chan struct{}, 10)
+	loader.OnConfigChange(func() { changed <- struct{}{} })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, loader.WatchConfigContext(ctx))
+
+	// Изменения других файлов в директории не вызывают callback
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(configPath), "other.yaml"), []byte("x: 1\n"), 0o644))
+	require.NoError(t, os.WriteFile(configPath, []byte("name: app\nport: 9090\n"), 0o644))
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("OnConfigChange callback not called after file change")
+	}
+
+	// После отмены ctx изменения не отслеживаются
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	for len(changed) > 0 {
+		<-changed
+	}
+	require.NoError(t, os.WriteFile(configPath, []byte("name: app\nport: 7070\n"), 0o644))
+	select {
+	case <-changed:
+		t.Fatal("OnConfigChange callback called after ctx was cancelled")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestLoader_WatchConfigContextMissingDir(t *testing.T) {
+	err := NewLoader("/nonexistent-dir/config.yaml").WatchConfigContext(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// DefaultTag тег структуры со значением по умолчанию для поля
+const DefaultTag = "default"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyDefaults регистрирует в v значения из тегов default полей cfg.
+// Ключи строятся так же, как при unmarshal: по тегу mapstructure или имени
+// поля в нижнем регистре. Вложенные структуры обходятся рекурсивно, указатели
+// на структуры пропускаются: значение по умолчанию создало бы опциональную
+// секцию, отсутствующую в файле.
+func applyDefaults(v *viper.Viper, cfg any) error {
+	t := reflect.TypeOf(cfg)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return applyStructDefaults(v, t, "")
+}
+
+func applyStructDefaults(v *viper.Viper, t reflect.Type, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+
+		key := prefix
+		if !strings.Contains(opts, "squash") {
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			if prefix != "" {
+				key = prefix + "."
+			}
+			key += name
+		}
+
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			if err := applyStructDefaults(v, field.Type, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		raw, ok := field.Tag.Lookup(DefaultTag)
+		if !ok {
+			continue
+		}
+		value, err := parseDefault(field.Type, raw)
+		if err != nil {
+			return fmt.Errorf("invalid default for %s: %w", key, err)
+		}
+		v.SetDefault(key, value)
+	}
+	return nil
+}
+
+// parseDefault преобразует значение тега default к типу поля
+func parseDefault(t reflect.Type, raw string) (any, error) {
+	if t == durationType {
+		return time.ParseDuration(raw)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(raw, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(raw, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(raw, t.Bits())
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.String {
+			break
+		}
+		if raw == "" {
+			return []string{}, nil
+		}
+		return strings.Split(raw, ","), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// ProviderConsul провайдер конфигурации Consul KV
+	ProviderConsul = "consul"
+	// ProviderEtcd провайдер конфигурации etcd (API v2)
+	ProviderEtcd = "etcd"
+	// ProviderEtcd3 провайдер конфигурации etcd (API v3)
+	ProviderEtcd3 = "etcd3"
+
+	// DefaultRemoteWatchInterval период опроса удаленного хранилища
+	DefaultRemoteWatchInterval = 5 * time.Second
+)
+
+// NewRemoteLoader создает загрузчик, читающий конфигурацию из Consul или etcd.
+// path - ключ в хранилище; формат определяется по его расширению (yaml по
+// умолчанию). Переменные окружения APP_* переопределяют значения так же, как
+// и для файлового загрузчика.
+//
+// Клиенты Consul и etcd подключаются отдельным модулем, чтобы не тянуть их
+// во все сервисы: импортируйте gitlab.com/zynero/shared/config/remote.
+func NewRemoteLoader(provider, endpoint, path string) (*Loader, error) {
+	if viper.RemoteConfig == nil {
+		return nil, fmt.Errorf("%w: remote providers are not registered, import gitlab.com/zynero/shared/config/remote", ErrRemoteConfig)
+	}
+
+	v := viper.New()
+
+	if err := v.AddRemoteProvider(provider, endpoint, path); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrConfigInvalid, err)
+	}
+
+	v.SetConfigType(remoteConfigType(path))
+	v.AutomaticEnv()
+	v.SetEnvPrefix("APP")
+
+	return &Loader{
+		viper:  v,
+		remote: true,
+	}, nil
+}
+
+// remoteConfigType возвращает формат конфигурации по расширению ключа
+func remoteConfigType(path string) string {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	for _, supported := range viper.SupportedExts {
+		if ext == supported {
+			return ext
+		}
+	}
+	return "yaml"
+}
+
+// readRemoteConfig читает конфигурацию из удаленного хранилища
+func (l *Loader) readRemoteConfig() error {
+	if err := l.viper.ReadRemoteConfig(); err != nil {
+		return fmt.Errorf("%w: %v", ErrRemoteConfig, err)
+	}
+	return nil
+}
+
+// WatchRemoteConfig опрашивает удаленное хранилище с периодом interval до
+// отмены ctx и вызывает callback из OnConfigChange, когда конфигурация
+// изменилась. Ошибки опроса не прерывают наблюдение.
+func (l *Loader) WatchRemoteConfig(ctx context.Context, interval time.Duration) error {
+	if !l.remote {
+		return fmt.Errorf("%w: loader is not configured with a remote provider", ErrConfigInvalid)
+	}
+	if interval <= 0 {
+		interval = DefaultRemoteWatchInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			before := l.viper.AllSettings()
+			if err := l.viper.WatchRemoteConfig(); err != nil {
+				continue
+			}
+			if l.onChange != nil && !reflect.DeepEqual(before, l.viper.AllSettings()) {
+				l.onChange()
+			}
+		}
+	}()
+
+	return nil
+}
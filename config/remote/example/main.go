@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"gitlab.com/zynero/shared/config"
+	_ "gitlab.com/zynero/shared/config/remote"
+)
+
+// AppConfig конфигурация сервиса, хранящаяся в Consul
+type AppConfig struct {
+	Name     string        `mapstructure:"name" default:"orders"`
+	Interval time.Duration `mapstructure:"interval" default:"10s"`
+}
+
+func (c *AppConfig) Validate() error {
+	if c.Interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+	return nil
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// 1. Загрузчик читает ключ services/orders/config.yaml из Consul KV
+	loader, err := config.NewRemoteLoader(config.ProviderConsul, "localhost:8500", "services/orders/config.yaml")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var cfg AppConfig
+	if err := loader.Load(&cfg); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("config loaded: name=%s interval=%s", cfg.Name, cfg.Interval)
+
+	// 2. Изменения ключа в Consul подхватываются опросом
+	loader.OnConfigChange(func() {
+		var updated AppConfig
+		if err := loader.Load(&updated); err != nil {
+			log.Printf("reload config: %v", err)
+			return
+		}
+		log.Printf("config changed: name=%s interval=%s", updated.Name, updated.Interval)
+	})
+	if err := loader.WatchRemoteConfig(ctx, config.DefaultRemoteWatchInterval); err != nil {
+		log.Fatal(err)
+	}
+
+	<-ctx.Done()
+}
@@ -0,0 +1,13 @@
+// Package remote подключает провайдеры Consul и etcd (v2 и v3) для
+// config.NewRemoteLoader. Клиенты этих хранилищ вынесены в отдельный модуль,
+// чтобы сервисы, читающие конфигурацию из файлов, их не загружали.
+//
+// Достаточно пустого импорта в main:
+//
+//	import _ "gitlab.com/zynero/shared/config/remote"
+package remote
+
+import (
+	// Регистрирует провайдеры consul/etcd/etcd3 для viper
+	_ "github.com/spf13/viper/remote"
+)
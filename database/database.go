@@ -33,6 +33,12 @@ type Database struct {
 
 // New создает новый экземпляр менеджера подключения к базе данных
 func New(cfg Config) (*Database, error) {
+	return NewContext(context.Background(), cfg)
+}
+
+// NewContext создает менеджер подключения к базе данных. Создание пула и
+// проверка подключения прерываются при отмене ctx.
+func NewContext(ctx context.Context, cfg Config) (*Database, error) {
 	// Формируем строку подключения
 	connString := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
@@ -54,13 +60,14 @@ func New(cfg Config) (*Database, error) {
 	poolConfig.ConnConfig.ConnectTimeout = cfg.Timeout
 
 	// Создаем пул соединений
-	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
 
 	// Проверяем подключение
-	if err := pool.Ping(context.Background()); err != nil {
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
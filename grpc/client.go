@@ -2,8 +2,12 @@ package grpc
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
 
+	platformlogger "gitlab.com/zynero/shared/logger"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
@@ -12,9 +16,58 @@ type Client struct {
 	conn *grpc.ClientConn
 }
 
-// Dial creates a Client connected to the given target.
+// Dial creates a Client connected to the given target using insecure
+// credentials. It is intended for local and in-cluster plaintext use.
 func Dial(ctx context.Context, target string, opts ...grpc.DialOption) (*Client, error) {
-	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	return dial(ctx, target, insecure.NewCredentials(), opts...)
+}
+
+// DialTLS creates a Client connected to the given target using TLS transport
+// credentials built from tlsCfg.
+func DialTLS(ctx context.Context, target string, tlsCfg *tls.Config, opts ...grpc.DialOption) (*Client, error) {
+	return dial(ctx, target, credentials.NewTLS(tlsCfg), opts...)
+}
+
+// DialTLSFromFile creates a Client connected to the given target using the CA
+// certificate in caFile to verify the server. serverName overrides the name
+// used for verification and may be empty to use the target host.
+func DialTLSFromFile(ctx context.Context, target, caFile, serverName string, opts ...grpc.DialOption) (*Client, error) {
+	creds, err := credentials.NewClientTLSFromFile(caFile, serverName)
+	if err != nil {
+		return nil, fmt.Errorf("load CA file %s: %w", caFile, err)
+	}
+	return dial(ctx, target, creds, opts...)
+}
+
+// DialWithDefaults creates a Client like Dial with the default client-side
+// logging and metrics interceptors installed.
+func DialWithDefaults(ctx context.Context, target string, l *platformlogger.Logger, opts ...grpc.DialOption) (*Client, error) {
+	return Dial(ctx, target, append(DefaultClientOptions(l), opts...)...)
+}
+
+// DefaultClientOptions returns dial options installing the default client-side
+// logging and metrics interceptors. They can be combined with DialTLS.
+func DefaultClientOptions(l *platformlogger.Logger) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(
+			LoggingUnaryClientInterceptor(l),
+			MetricsUnaryClientInterceptor(),
+		),
+		grpc.WithChainStreamInterceptor(
+			LoggingStreamClientInterceptor(l),
+			MetricsStreamClientInterceptor(),
+		),
+	}
+}
+
+// WithRetry returns a dial option retrying unary calls on transient failures
+// according to policy.
+func WithRetry(policy RetryPolicy) grpc.DialOption {
+	return grpc.WithChainUnaryInterceptor(RetryUnaryClientInterceptor(policy))
+}
+
+func dial(ctx context.Context, target string, creds credentials.TransportCredentials, opts ...grpc.DialOption) (*Client, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, opts...)
 	conn, err := grpc.DialContext(ctx, target, opts...)
 	if err != nil {
 		return nil, err
@@ -25,5 +78,16 @@ func Dial(ctx context.Context, target string, opts ...grpc.DialOption) (*Client,
 // Conn returns the underlying ClientConn.
 func (c *Client) Conn() *grpc.ClientConn { return c.conn }
 
+// NewBidiStream opens a bidirectional stream for the given full method name,
+// passing through the client's stream interceptors.
+func (c *Client) NewBidiStream(ctx context.Context, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	desc := &grpc.StreamDesc{
+		StreamName:    method,
+		ServerStreams: true,
+		ClientStreams: true,
+	}
+	return c.conn.NewStream(ctx, desc, method, opts...)
+}
+
 // Close closes the underlying connection.
 func (c *Client) Close() error { return c.conn.Close() }
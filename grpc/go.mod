@@ -3,9 +3,12 @@ module gitlab.com/zynero/shared/grpc
 go 1.24.2
 
 require (
+	github.com/google/uuid v1.6.0
 	github.com/grpc-ecosystem/go-grpc-middleware v1.4.0
 	github.com/grpc-ecosystem/go-grpc-prometheus v1.2.0
-	gitlab.com/zynero/shared/logger v0.1.20
+	github.com/prometheus/client_golang v1.22.0
+	gitlab.com/zynero/shared/logger v0.1.21
+	golang.org/x/time v0.12.0
 	google.golang.org/grpc v1.73.0
 )
 
@@ -15,7 +18,6 @@ require (
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
-	github.com/prometheus/client_golang v1.22.0 // indirect
 	github.com/prometheus/client_model v0.6.2 // indirect
 	github.com/prometheus/common v0.64.0 // indirect
 	github.com/prometheus/procfs v0.16.1 // indirect
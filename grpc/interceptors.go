@@ -2,19 +2,71 @@ package grpc
 
 import (
 	"context"
+	"runtime/debug"
 	"time"
 
 	platformlogger "gitlab.com/zynero/shared/logger"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// RecoveryUnaryInterceptor returns a unary server interceptor that recovers
+// from handler panics, logs them with the stack trace and returns
+// codes.Internal. When repanic is true the panic is propagated after logging,
+// which is useful in tests.
+func RecoveryUnaryInterceptor(l *platformlogger.Logger, repanic bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = handlePanic(l, info.FullMethod, r, repanic)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStreamInterceptor returns a stream server interceptor that recovers
+// from handler panics like RecoveryUnaryInterceptor.
+func RecoveryStreamInterceptor(l *platformlogger.Logger, repanic bool) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = handlePanic(l, info.FullMethod, r, repanic)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// handlePanic logs a recovered panic and converts it to a codes.Internal error.
+func handlePanic(l *platformlogger.Logger, method string, r any, repanic bool) error {
+	if l == nil {
+		l = platformlogger.GetGlobal()
+	}
+	l.Error().
+		Str("method", method).
+		Interface("panic", r).
+		Str("stack", string(debug.Stack())).
+		Msg("grpc handler panic")
+	if repanic {
+		panic(r)
+	}
+	return status.Errorf(codes.Internal, "internal error")
+}
+
 // LoggingUnaryInterceptor returns a unary server interceptor for logging.
 func LoggingUnaryInterceptor(l *platformlogger.Logger) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 		start := time.Now()
 		resp, err := handler(ctx, req)
 		if l != nil {
-			l.Info().Str("method", info.FullMethod).Dur("duration", time.Since(start)).Err(err).Msg("grpc request")
+			l.Info().
+				Str("method", info.FullMethod).
+				Str(platformlogger.RequestIDField, RequestIDFromContext(ctx)).
+				Dur("duration", time.Since(start)).
+				Err(err).
+				Msg("grpc request")
 		}
 		return resp, err
 	}
@@ -26,8 +78,78 @@ func LoggingStreamInterceptor(l *platformlogger.Logger) grpc.StreamServerInterce
 		start := time.Now()
 		err := handler(srv, ss)
 		if l != nil {
-			l.Info().Str("method", info.FullMethod).Dur("duration", time.Since(start)).Err(err).Msg("grpc stream")
+			l.Info().
+				Str("method", info.FullMethod).
+				Str(platformlogger.RequestIDField, RequestIDFromContext(ss.Context())).
+				Dur("duration", time.Since(start)).
+				Err(err).
+				Msg("grpc stream")
+		}
+		return err
+	}
+}
+
+// LoggingUnaryClientInterceptor returns a unary client interceptor for logging
+// outbound calls with their method, resulting status code and duration.
+func LoggingUnaryClientInterceptor(l *platformlogger.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if l != nil {
+			l.Info().
+				Str("method", method).
+				Str("target", cc.Target()).
+				Str("code", status.Code(err).String()).
+				Dur("duration", time.Since(start)).
+				Err(err).
+				Msg("grpc client request")
 		}
 		return err
 	}
 }
+
+// LoggingStreamClientInterceptor returns a stream client interceptor logging
+// the outcome of establishing outbound streams.
+func LoggingStreamClientInterceptor(l *platformlogger.Logger) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if l != nil {
+			l.Info().
+				Str("method", method).
+				Str("target", cc.Target()).
+				Str("code", status.Code(err).String()).
+				Dur("duration", time.Since(start)).
+				Err(err).
+				Msg("grpc client stream")
+		}
+		return cs, err
+	}
+}
+
+// RetryUnaryClientInterceptor returns a unary client interceptor retrying
+// calls that fail with codes.Unavailable or codes.DeadlineExceeded, using
+// policy for the number of retries and backoff between them.
+func RetryUnaryClientInterceptor(policy RetryPolicy) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		for attempt := 0; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || attempt >= policy.MaxRetries || !isRetryableCode(status.Code(err)) || ctx.Err() != nil {
+				return err
+			}
+
+			timer := time.NewTimer(policy.Backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// isRetryableCode reports whether a call failing with code is worth retrying.
+func isRetryableCode(code codes.Code) bool {
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
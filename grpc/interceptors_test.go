@@ -0,0 +1,74 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecoveryUnaryInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Panic"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	}
+
+	_, err := RecoveryUnaryInterceptor(nil, false)(context.Background(), nil, info, handler)
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("error code = %v, want %v", status.Code(err), codes.Internal)
+	}
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("recovered %v, want re-panic with boom", r)
+		}
+	}()
+	RecoveryUnaryInterceptor(nil, true)(context.Background(), nil, info, handler)
+}
+
+func TestLimiterRejectsOverConcurrency(t *testing.T) {
+	limiter, err := NewLimiter(Config{MaxConcurrentRequests: 1}, prometheus.NewRegistry())
+	if err != nil {
+		t.Fatalf("NewLimiter() error = %v", err)
+	}
+
+	release, err := limiter.acquire("/test.Service/Call")
+	if err != nil {
+		t.Fatalf("first acquire error = %v", err)
+	}
+
+	if _, err := limiter.acquire("/test.Service/Call"); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("second acquire code = %v, want %v", status.Code(err), codes.ResourceExhausted)
+	}
+
+	release()
+	if _, err := limiter.acquire("/test.Service/Call"); err != nil {
+		t.Errorf("acquire after release error = %v", err)
+	}
+}
+
+func TestRetryUnaryClientInterceptor(t *testing.T) {
+	interceptor := RetryUnaryClientInterceptor(RetryPolicy{MaxRetries: 2})
+
+	calls := 0
+	unavailable := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		calls++
+		return status.Error(codes.Unavailable, "down")
+	}
+	err := interceptor(context.Background(), "/test.Service/Call", nil, nil, nil, unavailable)
+	if status.Code(err) != codes.Unavailable || calls != 3 {
+		t.Errorf("code = %v, calls = %d, want Unavailable after 3 calls", status.Code(err), calls)
+	}
+
+	calls = 0
+	invalid := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		calls++
+		return status.Error(codes.InvalidArgument, "bad request")
+	}
+	if err := interceptor(context.Background(), "/test.Service/Call", nil, nil, nil, invalid); status.Code(err) != codes.InvalidArgument || calls != 1 {
+		t.Errorf("code = %v, calls = %d, want InvalidArgument without retries", status.Code(err), calls)
+	}
+}
@@ -0,0 +1,120 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimit configures a token bucket for a single method.
+type RateLimit struct {
+	Rate  float64 `mapstructure:"rate"`  // tokens per second
+	Burst int     `mapstructure:"burst"` // bucket size
+}
+
+// Rejection reasons used as the reason label of the rejected requests counter.
+const (
+	rejectReasonConcurrency = "concurrency"
+	rejectReasonRateLimit   = "rate_limit"
+)
+
+// Limiter sheds load by rejecting requests with codes.ResourceExhausted when
+// the number of in-flight requests or a method's rate limit is exceeded.
+type Limiter struct {
+	sem      chan struct{}
+	methods  map[string]*rate.Limiter
+	rejected *prometheus.CounterVec
+}
+
+// NewLimiter creates a Limiter from cfg.MaxConcurrentRequests and
+// cfg.MethodRateLimits and registers its rejected requests counter on reg.
+// A nil reg uses the default registerer. Method names are matched
+// case-insensitively because config keys are lower-cased when loaded.
+func NewLimiter(cfg Config, reg prometheus.Registerer) (*Limiter, error) {
+	l := &Limiter{
+		methods: make(map[string]*rate.Limiter, len(cfg.MethodRateLimits)),
+	}
+	if cfg.MaxConcurrentRequests > 0 {
+		l.sem = make(chan struct{}, cfg.MaxConcurrentRequests)
+	}
+	for method, rl := range cfg.MethodRateLimits {
+		l.methods[strings.ToLower(method)] = rate.NewLimiter(rate.Limit(rl.Rate), rl.Burst)
+	}
+
+	rejected := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_server_rejected_requests_total",
+			Help: "Total number of gRPC requests rejected by the server limiter.",
+		},
+		[]string{"grpc_method", "reason"},
+	)
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	if err := reg.Register(rejected); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			return nil, err
+		}
+		existing, ok := are.ExistingCollector.(*prometheus.CounterVec)
+		if !ok {
+			return nil, err
+		}
+		rejected = existing
+	}
+	l.rejected = rejected
+
+	return l, nil
+}
+
+// UnaryServerInterceptor returns a unary server interceptor applying the limits.
+func (l *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		release, err := l.acquire(info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a stream server interceptor applying the
+// limits for the lifetime of each stream.
+func (l *Limiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		release, err := l.acquire(info.FullMethod)
+		if err != nil {
+			return err
+		}
+		defer release()
+		return handler(srv, ss)
+	}
+}
+
+// acquire admits a request for method, returning a function releasing its
+// concurrency slot, or a ResourceExhausted error if it must be rejected.
+func (l *Limiter) acquire(method string) (func(), error) {
+	if rl, ok := l.methods[strings.ToLower(method)]; ok && !rl.Allow() {
+		l.rejected.WithLabelValues(method, rejectReasonRateLimit).Inc()
+		return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %s", method)
+	}
+
+	if l.sem == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.sem <- struct{}{}:
+		return func() { <-l.sem }, nil
+	default:
+		l.rejected.WithLabelValues(method, rejectReasonConcurrency).Inc()
+		return nil, status.Error(codes.ResourceExhausted, "too many concurrent requests")
+	}
+}
@@ -1,7 +1,10 @@
 package grpc
 
 import (
+	"errors"
+
 	"github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
 )
 
@@ -14,3 +17,94 @@ func MetricsUnaryInterceptor() grpc.UnaryServerInterceptor {
 func MetricsStreamInterceptor() grpc.StreamServerInterceptor {
 	return grpc_prometheus.StreamServerInterceptor
 }
+
+// MetricsUnaryClientInterceptor provides Prometheus metrics for outbound unary
+// calls, labelled by method and resulting status code.
+func MetricsUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return grpc_prometheus.UnaryClientInterceptor
+}
+
+// MetricsStreamClientInterceptor provides Prometheus metrics for outbound
+// streams.
+func MetricsStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return grpc_prometheus.StreamClientInterceptor
+}
+
+// Metrics collects gRPC server metrics registered on a provided registerer,
+// mirroring how the metrics package collects HTTP metrics.
+type Metrics struct {
+	server *grpc_prometheus.ServerMetrics
+}
+
+// NewMetrics creates gRPC server metrics as described by NewServerMetrics.
+func NewMetrics(cfg Config, reg prometheus.Registerer) (*Metrics, error) {
+	server, err := NewServerMetrics(cfg, reg)
+	if err != nil {
+		return nil, err
+	}
+	return &Metrics{server: server}, nil
+}
+
+// UnaryServerInterceptor records metrics for unary calls.
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return m.server.UnaryServerInterceptor()
+}
+
+// StreamServerInterceptor records metrics for streams.
+func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return m.server.StreamServerInterceptor()
+}
+
+// InitializeMetrics pre-populates metrics for all services registered on srv.
+func (m *Metrics) InitializeMetrics(srv *grpc.Server) {
+	m.server.InitializeMetrics(srv)
+}
+
+// ServerMetrics returns the underlying grpc_prometheus collector.
+func (m *Metrics) ServerMetrics() *grpc_prometheus.ServerMetrics {
+	return m.server
+}
+
+// NewServerMetrics creates gRPC server metrics with handling time histograms
+// enabled, using cfg.HistogramBuckets when set, and registers them on reg.
+// Metric names are prefixed with cfg.MetricsNamespace when set. A nil reg
+// without namespace selects the grpc_prometheus default metrics, which are
+// already registered on the default registry; with a namespace the metrics
+// are registered on the default registry. If reg already holds the same gRPC
+// server metrics, the existing collector is returned instead of failing, so
+// several servers may share one registry.
+func NewServerMetrics(cfg Config, reg prometheus.Registerer) (*grpc_prometheus.ServerMetrics, error) {
+	var histogramOpts []grpc_prometheus.HistogramOption
+	if len(cfg.HistogramBuckets) > 0 {
+		histogramOpts = append(histogramOpts, grpc_prometheus.WithHistogramBuckets(cfg.HistogramBuckets))
+	}
+
+	if reg == nil && cfg.MetricsNamespace == "" {
+		// Registering the histogram again is ignored, so repeated calls are safe.
+		grpc_prometheus.EnableHandlingTimeHistogram(histogramOpts...)
+		return grpc_prometheus.DefaultServerMetrics, nil
+	}
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	var counterOpts []grpc_prometheus.CounterOption
+	if ns := cfg.MetricsNamespace; ns != "" {
+		counterOpts = append(counterOpts, func(o *prometheus.CounterOpts) { o.Namespace = ns })
+		histogramOpts = append(histogramOpts, func(o *prometheus.HistogramOpts) { o.Namespace = ns })
+	}
+
+	m := grpc_prometheus.NewServerMetrics(counterOpts...)
+	m.EnableHandlingTimeHistogram(histogramOpts...)
+
+	if err := reg.Register(m); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(*grpc_prometheus.ServerMetrics); ok {
+				return existing, nil
+			}
+		}
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,61 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDMetadataKey is the metadata key carrying the request ID.
+const RequestIDMetadataKey = "x-request-id"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, or an empty
+// string if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDUnaryInterceptor returns a unary server interceptor that reads the
+// request ID from incoming metadata, generating one if absent, stores it in
+// the handler context and echoes it back in the response trailer.
+func RequestIDUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id := incomingRequestID(ctx)
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(RequestIDMetadataKey, id))
+		return handler(ContextWithRequestID(ctx, id), req)
+	}
+}
+
+// RequestIDStreamInterceptor returns a stream server interceptor with the same
+// behaviour as RequestIDUnaryInterceptor.
+func RequestIDStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		id := incomingRequestID(ss.Context())
+		ss.SetTrailer(metadata.Pairs(RequestIDMetadataKey, id))
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ContextWithRequestID(ss.Context(), id)
+		return handler(srv, wrapped)
+	}
+}
+
+// incomingRequestID extracts the request ID from incoming metadata or
+// generates a new one.
+func incomingRequestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadataKey); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+	return uuid.NewString()
+}
@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy configures retries of unary client calls.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int `mapstructure:"max_retries"`
+	// BaseDelay is the delay before the first retry.
+	BaseDelay time.Duration `mapstructure:"base_delay"`
+	// MaxDelay caps the delay between retries, zero means no cap.
+	MaxDelay time.Duration `mapstructure:"max_delay"`
+	// BackoffFactor multiplies the delay after each retry.
+	BackoffFactor float64 `mapstructure:"backoff_factor"`
+	// Jitter randomly reduces each delay by up to half.
+	Jitter bool `mapstructure:"jitter"`
+}
+
+// DefaultRetryPolicy returns a policy with 3 retries and exponential backoff
+// from 100ms up to 2s.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:    3,
+		BaseDelay:     100 * time.Millisecond,
+		MaxDelay:      2 * time.Second,
+		BackoffFactor: 2.0,
+		Jitter:        true,
+	}
+}
+
+// Backoff returns the delay before the retry with zero-based number attempt.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < attempt; i++ {
+		delay = time.Duration(float64(delay) * p.BackoffFactor)
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter && delay > 1 {
+		half := delay / 2
+		delay = half + time.Duration(rand.Int64N(int64(half)+1))
+	}
+	return delay
+}
@@ -2,20 +2,24 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"net"
 	"time"
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
-	grpc_prom "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/prometheus/client_golang/prometheus"
 	platformlogger "gitlab.com/zynero/shared/logger"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
 )
 
 // Config represents gRPC server configuration.
 type Config struct {
-	Enabled               bool          `mapstructure:"enabled" default:"true"`
+	Enabled               *bool         `mapstructure:"enabled"` // nil means enabled
 	Address               string        `mapstructure:"address"`
 	Timeout               time.Duration `mapstructure:"timeout"`
 	TLSCertFile           string        `mapstructure:"tls_cert_file"`
@@ -26,17 +30,50 @@ type Config struct {
 	KeepAliveTimeout      time.Duration `mapstructure:"keep_alive_timeout"`
 	EnforcementMinTime    time.Duration `mapstructure:"enforcement_min_time"`
 	EnforcementPermit     bool          `mapstructure:"enforcement_permit"`
+	HistogramBuckets      []float64     `mapstructure:"histogram_buckets"`  // handling time histogram buckets
+	RepanicOnRecover      bool          `mapstructure:"repanic_on_recover"` // propagate handler panics, for tests
+	HealthService         bool          `mapstructure:"health_service"`     // register grpc.health.v1
+	Reflection            bool          `mapstructure:"reflection"`         // register server reflection
+
+	// Load shedding; zero values disable the corresponding limit.
+	MaxConcurrentRequests int                  `mapstructure:"max_concurrent_requests"`
+	MethodRateLimits      map[string]RateLimit `mapstructure:"method_rate_limits"` // keyed by full method name
+
+	// MetricsNamespace prefixes gRPC metric names, typically the service name.
+	MetricsNamespace string `mapstructure:"metrics_namespace"`
+	// MetricsRegisterer receives the server metrics, e.g. the metrics package
+	// registry. Nil uses the default registry.
+	MetricsRegisterer prometheus.Registerer `mapstructure:"-"`
+}
+
+// enabled reports whether the server should be built. A nil Enabled keeps
+// configs built in code enabled by default.
+func (c Config) enabled() bool {
+	return c.Enabled == nil || *c.Enabled
 }
 
 // Server wraps a grpc.Server with additional configuration.
 type Server struct {
-	srv    *grpc.Server
-	lis    net.Listener
-	config Config
+	srv     *grpc.Server
+	lis     net.Listener
+	config  Config
+	metrics *Metrics
+	health  *health.Server
 }
 
-// NewServer creates a new gRPC server with default interceptors.
+// NewServer creates a new gRPC server with default interceptors. When
+// cfg.Enabled is set to false a disabled server is returned whose Start and
+// Stop are no-ops.
 func NewServer(cfg Config, l *platformlogger.Logger, opts ...grpc.ServerOption) (*Server, error) {
+	if !cfg.enabled() {
+		return &Server{config: cfg}, nil
+	}
+
+	metrics, err := NewMetrics(cfg, cfg.MetricsRegisterer)
+	if err != nil {
+		return nil, err
+	}
+
 	kp := keepalive.EnforcementPolicy{
 		MinTime:             cfg.EnforcementMinTime,
 		PermitWithoutStream: cfg.EnforcementPermit,
@@ -48,18 +85,35 @@ func NewServer(cfg Config, l *platformlogger.Logger, opts ...grpc.ServerOption)
 		MaxConnectionAgeGrace: cfg.MaxConnectionAgeGrace,
 	}
 
+	unary := []grpc.UnaryServerInterceptor{
+		RecoveryUnaryInterceptor(l, cfg.RepanicOnRecover),
+		RequestIDUnaryInterceptor(),
+		LoggingUnaryInterceptor(l),
+		metrics.UnaryServerInterceptor(),
+	}
+	stream := []grpc.StreamServerInterceptor{
+		RecoveryStreamInterceptor(l, cfg.RepanicOnRecover),
+		RequestIDStreamInterceptor(),
+		LoggingStreamInterceptor(l),
+		metrics.StreamServerInterceptor(),
+	}
+
+	// The limiter runs last so rejected requests are still logged and measured.
+	if cfg.MaxConcurrentRequests > 0 || len(cfg.MethodRateLimits) > 0 {
+		limiter, err := NewLimiter(cfg, cfg.MetricsRegisterer)
+		if err != nil {
+			return nil, err
+		}
+		unary = append(unary, limiter.UnaryServerInterceptor())
+		stream = append(stream, limiter.StreamServerInterceptor())
+	}
+
 	serverOpts := []grpc.ServerOption{
 		grpc.ConnectionTimeout(cfg.Timeout),
 		grpc.KeepaliveEnforcementPolicy(kp),
 		grpc.KeepaliveParams(ka),
-		grpc_middleware.WithUnaryServerChain(
-			LoggingUnaryInterceptor(l),
-			MetricsUnaryInterceptor(),
-		),
-		grpc_middleware.WithStreamServerChain(
-			LoggingStreamInterceptor(l),
-			MetricsStreamInterceptor(),
-		),
+		grpc_middleware.WithUnaryServerChain(unary...),
+		grpc_middleware.WithStreamServerChain(stream...),
 	}
 
 	if cfg.TLSCertFile != "" && cfg.TLSKeyFile != "" {
@@ -77,22 +131,96 @@ func NewServer(cfg Config, l *platformlogger.Logger, opts ...grpc.ServerOption)
 	}
 
 	srv := grpc.NewServer(serverOpts...)
-	return &Server{srv: srv, config: cfg}, nil
+	s := &Server{srv: srv, config: cfg, metrics: metrics}
+	if cfg.HealthService {
+		s.WithHealthService()
+	}
+	if cfg.Reflection {
+		s.WithReflection()
+	}
+	return s, nil
 }
 
-// Start begins serving on the configured address.
-func (s *Server) Start() error {
-	var err error
-	s.lis, err = net.Listen("tcp", s.config.Address)
+// WithHealthService registers the standard grpc.health.v1 service, reporting
+// SERVING for the whole server. It is a no-op if already registered.
+func (s *Server) WithHealthService() *Server {
+	if s.srv == nil || s.health != nil {
+		return s
+	}
+	s.health = health.NewServer()
+	healthpb.RegisterHealthServer(s.srv, s.health)
+	return s
+}
+
+// WithReflection registers the server reflection service used by grpcurl.
+func (s *Server) WithReflection() *Server {
+	if s.srv == nil {
+		return s
+	}
+	reflection.Register(s.srv)
+	return s
+}
+
+// SetServingStatus sets the health status reported for service. An empty
+// service name denotes the whole server. It is a no-op unless the health
+// service is registered.
+func (s *Server) SetServingStatus(service string, status healthpb.HealthCheckResponse_ServingStatus) {
+	if s.health == nil {
+		return
+	}
+	s.health.SetServingStatus(service, status)
+}
+
+// Listen binds the configured address without serving. It allows callers to
+// learn the chosen port via Addr before Start, e.g. when listening on ":0".
+// Calling Listen on an already bound server is a no-op.
+func (s *Server) Listen() error {
+	if s.srv == nil || s.lis != nil {
+		return nil
+	}
+	lis, err := net.Listen("tcp", s.config.Address)
 	if err != nil {
 		return err
 	}
-	grpc_prom.Register(s.srv)
-	return s.srv.Serve(s.lis)
+	s.lis = lis
+	return nil
+}
+
+// Addr returns the bound listener address, or nil if the server is not
+// listening yet.
+func (s *Server) Addr() net.Addr {
+	if s.lis == nil {
+		return nil
+	}
+	return s.lis.Addr()
+}
+
+// Start begins serving on the bound listener, binding the configured address
+// first if Listen has not been called.
+func (s *Server) Start() error {
+	if s.srv == nil {
+		return nil
+	}
+	if err := s.Listen(); err != nil {
+		return err
+	}
+	s.metrics.InitializeMetrics(s.srv)
+	// A server stopped before serving is not an error for the caller.
+	if err := s.srv.Serve(s.lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
 }
 
 // Stop gracefully stops the gRPC server.
 func (s *Server) Stop(ctx context.Context) error {
+	if s.srv == nil {
+		return nil
+	}
+	// Report NOT_SERVING so health probes stop routing traffic while draining.
+	if s.health != nil {
+		s.health.Shutdown()
+	}
 	stopped := make(chan struct{})
 	go func() {
 		s.srv.GracefulStop()
@@ -107,5 +235,12 @@ func (s *Server) Stop(ctx context.Context) error {
 	}
 }
 
-// GRPCServer exposes the underlying *grpc.Server.
+// Enabled reports whether the server was created from an enabled config.
+func (s *Server) Enabled() bool { return s.srv != nil }
+
+// Metrics returns the server metrics. It is nil when the server is disabled.
+func (s *Server) Metrics() *Metrics { return s.metrics }
+
+// GRPCServer exposes the underlying *grpc.Server. It is nil when the server
+// is disabled.
 func (s *Server) GRPCServer() *grpc.Server { return s.srv }
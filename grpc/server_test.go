@@ -0,0 +1,110 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+)
+
+func TestNewServerDisabled(t *testing.T) {
+	enabled := false
+	s, err := NewServer(Config{Enabled: &enabled, Address: "127.0.0.1:0"}, nil)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+
+	if s.Enabled() {
+		t.Error("disabled server should report Enabled() == false")
+	}
+
+	if err := s.Start(); err != nil {
+		t.Errorf("Start() on disabled server error = %v", err)
+	}
+
+	if s.lis != nil {
+		t.Error("disabled server should not bind a listener")
+	}
+
+	if err := s.Stop(context.Background()); err != nil {
+		t.Errorf("Stop() on disabled server error = %v", err)
+	}
+}
+
+func TestServerListenAddr(t *testing.T) {
+	s, err := NewServer(Config{Address: "127.0.0.1:0"}, nil)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+
+	if s.Addr() != nil {
+		t.Error("Addr() should be nil before Listen")
+	}
+
+	if err := s.Listen(); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	addr, ok := s.Addr().(*net.TCPAddr)
+	if !ok || addr.Port == 0 {
+		t.Fatalf("Addr() = %v, want bound TCP address with ephemeral port", s.Addr())
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Start() }()
+
+	if err := s.Stop(context.Background()); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Errorf("Start() error = %v", err)
+	}
+}
+
+func TestNewServerSharedRegistry(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cfg := Config{Address: "127.0.0.1:0", MetricsRegisterer: reg}
+
+	first, err := NewServer(cfg, nil)
+	if err != nil {
+		t.Fatalf("first NewServer() error = %v", err)
+	}
+	second, err := NewServer(cfg, nil)
+	if err != nil {
+		t.Fatalf("second NewServer() error = %v", err)
+	}
+
+	if first.Metrics().ServerMetrics() != second.Metrics().ServerMetrics() {
+		t.Error("servers sharing a registry should share server metrics")
+	}
+}
+
+func TestNewMetricsNamespace(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewMetrics(Config{MetricsNamespace: "billing"}, reg)
+	if err != nil {
+		t.Fatalf("NewMetrics() error = %v", err)
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/billing.Invoices/Get"}
+	handler := func(ctx context.Context, req any) (any, error) { return nil, nil }
+	if _, err := m.UnaryServerInterceptor()(context.Background(), nil, info, handler); err != nil {
+		t.Fatalf("interceptor error = %v", err)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	if len(families) == 0 {
+		t.Fatal("no metrics gathered")
+	}
+	for _, mf := range families {
+		if !strings.HasPrefix(mf.GetName(), "billing_") {
+			t.Errorf("metric %s is not prefixed with the namespace", mf.GetName())
+		}
+	}
+}
@@ -2,7 +2,10 @@ module gitlab.com/zynero/shared/healthcheck
 
 go 1.24.2
 
-require gitlab.com/zynero/shared/logger v0.1.20
+require (
+	gitlab.com/zynero/shared/logger v0.1.20
+	gitlab.com/zynero/shared/observability v0.1.21
+)
 
 require (
 	github.com/mattn/go-colorable v0.1.14 // indirect
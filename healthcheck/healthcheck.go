@@ -1,23 +1,54 @@
 package healthcheck
 
 import (
-	"errors"
-	"fmt"
+	"context"
+	"encoding/json"
 	platformlogger "gitlab.com/zynero/shared/logger"
+	"gitlab.com/zynero/shared/observability"
 	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCheckTimeout общий таймаут проверок, если он не задан в конфигурации
+	defaultCheckTimeout = 5 * time.Second
+	// defaultShutdownTimeout таймаут graceful shutdown, если он не задан в конфигурации
+	defaultShutdownTimeout = 5 * time.Second
+)
+
+// Статусы ответа health-check
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
 )
 
 // Config представляет конфигурацию healthcheck
 type Config struct {
-	Enabled bool   `mapstructure:"enabled"`
-	Path    string `mapstructure:"path"`
-	Port    int    `mapstructure:"port"`
+	Enabled bool          `mapstructure:"enabled"`
+	Path    string        `mapstructure:"path"`
+	Port    int           `mapstructure:"port"`
+	Timeout time.Duration `mapstructure:"timeout"` // общий таймаут выполнения проверок
+
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
 }
 
+// CheckFunc проверяет доступность зависимости
+type CheckFunc func(ctx context.Context) error
+
 // Healthcheck представляет менеджер проверок здоровья
 type Healthcheck struct {
 	config Config
-	server *http.Server
+	server *observability.Server
+
+	mu     sync.RWMutex
+	checks map[string]CheckFunc
+}
+
+// response представляет тело ответа health-check
+type response struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
 }
 
 // New создает экземпляр health-check сервера
@@ -28,26 +59,44 @@ func New(cfg Config) (*Healthcheck, error) {
 
 	h := &Healthcheck{
 		config: cfg,
+		checks: make(map[string]CheckFunc),
 	}
 
-	mux := http.NewServeMux()
-	mux.HandleFunc(cfg.Path, h.handleHealthcheck)
-
-	h.server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.Port),
-		Handler: mux,
+	// Сервер разделяется с метриками, если они настроены на один порт.
+	server, err := observability.Acquire(cfg.Port)
+	if err != nil {
+		return nil, err
 	}
-
-	go func() {
-		platformlogger.Info().Msgf("Starting healthcheck server on %s", h.server.Addr)
-		if err := h.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			panic(fmt.Sprintf("Failed to start healthcheck server: %v", err))
-		}
-	}()
+	if err := server.Handle(cfg.Path, http.HandlerFunc(h.handleHealthcheck)); err != nil {
+		_ = server.Close()
+		return nil, err
+	}
+	h.server = server
+	platformlogger.Info().Msgf("Serving healthcheck on %s%s", server.Addr(), cfg.Path)
 
 	return h, nil
 }
 
+// AddCheck регистрирует проверку зависимости. Повторная регистрация
+// с тем же именем заменяет проверку.
+func (h *Healthcheck) AddCheck(name string, fn CheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.checks == nil {
+		h.checks = make(map[string]CheckFunc)
+	}
+	h.checks[name] = fn
+}
+
+// Errors возвращает канал ошибок HTTP-сервера, возникших после запуска.
+// Для отключенного компонента возвращает nil-канал.
+func (h *Healthcheck) Errors() <-chan error {
+	if h.server == nil {
+		return nil
+	}
+	return h.server.Errors()
+}
+
 // Stop останавливает HTTP-сервер проверок здоровья
 func (h *Healthcheck) Stop() error {
 	if !h.config.Enabled || h.server == nil {
@@ -56,8 +105,89 @@ func (h *Healthcheck) Stop() error {
 	return h.server.Close()
 }
 
+// Shutdown плавно останавливает HTTP-сервер, дожидаясь завершения активных
+// запросов. Если ctx истекает раньше, соединения закрываются принудительно.
+func (h *Healthcheck) Shutdown(ctx context.Context) error {
+	if !h.config.Enabled || h.server == nil {
+		return nil
+	}
+	return h.server.Shutdown(ctx)
+}
+
+// ShutdownTimeout возвращает таймаут graceful shutdown из конфигурации
+func (h *Healthcheck) ShutdownTimeout() time.Duration {
+	if h.config.ShutdownTimeout > 0 {
+		return h.config.ShutdownTimeout
+	}
+	return defaultShutdownTimeout
+}
+
+// runChecks параллельно выполняет все проверки с общим таймаутом и возвращает
+// ошибки по именам проверок
+func (h *Healthcheck) runChecks(ctx context.Context) map[string]error {
+	h.mu.RLock()
+	checks := make(map[string]CheckFunc, len(h.checks))
+	for name, fn := range h.checks {
+		checks[name] = fn
+	}
+	h.mu.RUnlock()
+
+	timeout := h.config.Timeout
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result, len(checks))
+	for name, fn := range checks {
+		go func() {
+			results <- result{name: name, err: fn(ctx)}
+		}()
+	}
+
+	errs := make(map[string]error, len(checks))
+	for range checks {
+		select {
+		case r := <-results:
+			errs[r.name] = r.err
+		case <-ctx.Done():
+			// Проверки, не успевшие завершиться, считаются неуспешными
+			for name := range checks {
+				if _, ok := errs[name]; !ok {
+					errs[name] = ctx.Err()
+				}
+			}
+			return errs
+		}
+	}
+	return errs
+}
+
 // handleHealthcheck обрабатывает запрос на проверку здоровья
 func (h *Healthcheck) handleHealthcheck(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("OK"))
+	resp := response{Status: StatusOK}
+	code := http.StatusOK
+
+	errs := h.runChecks(r.Context())
+	if len(errs) > 0 {
+		resp.Checks = make(map[string]string, len(errs))
+		for name, err := range errs {
+			if err != nil {
+				resp.Checks[name] = err.Error()
+				resp.Status = StatusDegraded
+				code = http.StatusServiceUnavailable
+			} else {
+				resp.Checks[name] = StatusOK
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
 }
@@ -0,0 +1,178 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// serve выполняет запрос к обработчику и разбирает JSON-ответ
+func serve(t *testing.T, h *Healthcheck) (int, response) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.handleHealthcheck(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var resp response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal body %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+func TestHealthcheckWithoutChecks(t *testing.T) {
+	code, resp := serve(t, &Healthcheck{})
+
+	if code != http.StatusOK || resp.Status != StatusOK || resp.Checks != nil {
+		t.Errorf("response = %d %+v, want 200 with status ok only", code, resp)
+	}
+}
+
+func TestHealthcheckReportsChecks(t *testing.T) {
+	h := &Healthcheck{}
+	h.AddCheck("postgres", func(context.Context) error { return nil })
+	h.AddCheck("redis", func(context.Context) error { return errors.New("connection refused") })
+
+	code, resp := serve(t, h)
+	if code != http.StatusServiceUnavailable || resp.Status != StatusDegraded {
+		t.Fatalf("response = %d %q, want 503 degraded", code, resp.Status)
+	}
+	if resp.Checks["postgres"] != StatusOK || resp.Checks["redis"] != "connection refused" {
+		t.Errorf("checks = %v", resp.Checks)
+	}
+
+	// Повторная регистрация заменяет проверку
+	h.AddCheck("redis", func(context.Context) error { return nil })
+	if code, resp := serve(t, h); code != http.StatusOK || resp.Status != StatusOK || len(resp.Checks) != 2 {
+		t.Errorf("response after replace = %d %+v, want 200 with two checks", code, resp)
+	}
+}
+
+func TestHealthcheckRunsChecksConcurrently(t *testing.T) {
+	h := &Healthcheck{config: Config{Timeout: 150 * time.Millisecond}}
+	for _, name := range []string{"a", "b", "c"} {
+		h.AddCheck(name, func(ctx context.Context) error {
+			select {
+			case <-time.After(100 * time.Millisecond):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}
+
+	// Последовательно проверки не уложились бы в общий таймаут
+	if code, resp := serve(t, h); code != http.StatusOK {
+		t.Errorf("response = %d %+v, want 200", code, resp)
+	}
+}
+
+func TestHealthcheckTimeout(t *testing.T) {
+	h := &Healthcheck{config: Config{Timeout: 50 * time.Millisecond}}
+	h.AddCheck("fast", func(context.Context) error { return nil })
+	h.AddCheck("stuck", func(context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	start := time.Now()
+	code, resp := serve(t, h)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("handler took %v, want it to stop at the timeout", elapsed)
+	}
+	if code != http.StatusServiceUnavailable || resp.Checks["stuck"] != context.DeadlineExceeded.Error() {
+		t.Errorf("response = %d %+v, want stuck check to time out", code, resp)
+	}
+	if resp.Checks["fast"] != StatusOK {
+		t.Errorf("fast check = %q, want ok", resp.Checks["fast"])
+	}
+}
+
+func TestShutdownWaitsForActiveRequest(t *testing.T) {
+	h, err := New(Config{Enabled: true, Path: "/health", Port: 0})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h.AddCheck("slow", func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	codes := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + h.server.Addr() + "/health")
+		if err != nil {
+			codes <- 0
+			return
+		}
+		resp.Body.Close()
+		codes <- resp.StatusCode
+	}()
+	<-started
+
+	done := make(chan error, 1)
+	go func() { done <- h.Shutdown(context.Background()) }()
+
+	// Shutdown ждет завершения активного запроса
+	select {
+	case err := <-done:
+		t.Fatalf("Shutdown() returned before the request finished: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if code := <-codes; code != http.StatusOK {
+		t.Errorf("active request status = %d, want 200", code)
+	}
+}
+
+func TestShutdownHonoursContext(t *testing.T) {
+	h, err := New(Config{Enabled: true, Path: "/health", Port: 0})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	started := make(chan struct{})
+	h.AddCheck("stuck", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	go func() {
+		if resp, err := http.Get("http://" + h.server.Addr() + "/health"); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := h.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestShutdownDisabled(t *testing.T) {
+	h, err := New(Config{Enabled: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := h.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() on disabled healthcheck error = %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/rs/zerolog"
 	"gitlab.com/zynero/shared/logger"
 )
 
@@ -119,14 +120,13 @@ func main() {
 	rawLogger.Info().Msg("Direct zerolog access when needed")
 
 	// 13. Производительность - использование событий без выполнения
-	event := logger.Debug() // создаем событие
-	if event != nil {       // проверяем, что оно не nil (уровень активен)
+	if logger.Enabled(zerolog.DebugLevel) { // проверяем, что уровень Debug активен
 		// выполняем дорогостоящие операции только если событие будет записано
 		expensiveOperation := func() string {
 			time.Sleep(1 * time.Millisecond) // имитация дорогой операции
 			return "expensive result"
 		}
-		event.Str("result", expensiveOperation()).Msg("Expensive operation completed")
+		logger.Debug().Str("result", expensiveOperation()).Msg("Expensive operation completed")
 	}
 
 	// 14. Пример обработки пользователей
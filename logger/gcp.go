@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// FormatGCP формат вывода для Google Cloud Logging (GKE, Cloud Run)
+const FormatGCP = "gcp"
+
+// GCPSourceLocationField поле, из которого Cloud Logging берет место вызова
+const GCPSourceLocationField = "logging.googleapis.com/sourceLocation"
+
+// applyGCPFormat настраивает конфигурацию под ожидания Cloud Logging:
+// уровень в поле severity, сообщение в message, время в RFC3339Nano
+func applyGCPFormat(cfg *Config) {
+	cfg.LevelFieldName = "severity"
+	cfg.MessageFieldName = "message"
+	cfg.LevelFormat = LevelFormatGCP
+	cfg.TimeFormat = time.RFC3339Nano
+}
+
+// loggerFile путь к файлу обертки, фреймы которого пропускаются при поиске места вызова
+var loggerFile = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return strings.TrimSuffix(file, "gcp.go") + "logger.go"
+}()
+
+// sourceLocationHook добавляет к событию sourceLocation в формате Cloud Logging.
+// Место вызова — первый фрейм вне zerolog и оберток этого пакета.
+type sourceLocationHook struct{}
+
+func (sourceLocationHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/rs/zerolog.") && frame.File != loggerFile {
+			e.Dict(GCPSourceLocationField, zerolog.Dict().
+				Str("file", frame.File).
+				Str("line", strconv.Itoa(frame.Line)).
+				Str("function", frame.Function))
+			return
+		}
+		if !more {
+			return
+		}
+	}
+}
@@ -2,10 +2,12 @@ module gitlab.com/zynero/shared/logger
 
 go 1.24.2
 
-require github.com/rs/zerolog v1.34.0
+require (
+	github.com/mattn/go-isatty v0.0.20
+	github.com/rs/zerolog v1.34.0
+)
 
 require (
 	github.com/mattn/go-colorable v0.1.14 // indirect
-	github.com/mattn/go-isatty v0.0.20 // indirect
 	golang.org/x/sys v0.33.0 // indirect
 )
@@ -5,25 +5,85 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/mattn/go-isatty"
 	"github.com/rs/zerolog"
 )
 
 var global *Logger
 
+// RequestIDField имя поля лога с идентификатором запроса, общее для HTTP и gRPC
+const RequestIDField = "request_id"
+
 // Config представляет конфигурацию логгера
 type Config struct {
 	Level      string `mapstructure:"level" json:"level" yaml:"level"`
-	Format     string `mapstructure:"format" json:"format" yaml:"format"` // json или console
+	Format     string `mapstructure:"format" json:"format" yaml:"format"` // json, console или gcp
 	Output     string `mapstructure:"output" json:"output" yaml:"output"` // stdout, stderr или путь к файлу
 	TimeFormat string `mapstructure:"time_format" json:"time_format" yaml:"time_format"`
 	CallerInfo bool   `mapstructure:"caller_info" json:"caller_info" yaml:"caller_info"` // добавлять информацию о вызывающем коде
+	// TimestampFieldName имя поля времени, по умолчанию "time"
+	TimestampFieldName string `mapstructure:"timestamp_field_name" json:"timestamp_field_name" yaml:"timestamp_field_name"`
+	// DisableTimestamp отключает поле времени, например если его добавляет платформа
+	DisableTimestamp bool `mapstructure:"disable_timestamp" json:"disable_timestamp" yaml:"disable_timestamp"`
+	// LevelFieldName имя поля уровня, по умолчанию "level"
+	LevelFieldName string `mapstructure:"level_field_name" json:"level_field_name" yaml:"level_field_name"`
+	// MessageFieldName имя поля сообщения, по умолчанию "message"
+	MessageFieldName string `mapstructure:"message_field_name" json:"message_field_name" yaml:"message_field_name"`
+	// LevelFormat формат значения уровня: lower (по умолчанию, "error"),
+	// upper ("ERROR") или gcp (severity Google Cloud Logging: "WARNING", "CRITICAL")
+	LevelFormat string `mapstructure:"level_format" json:"level_format" yaml:"level_format"`
+	// NoColor отключает цвета формата console. Если вывод не терминал,
+	// цвета отключаются автоматически
+	NoColor bool `mapstructure:"no_color" json:"no_color" yaml:"no_color"`
+	// PartsOrder порядок частей записи формата console, например
+	// ["time", "level", "message"]; по умолчанию порядок zerolog
+	PartsOrder []string `mapstructure:"parts_order" json:"parts_order" yaml:"parts_order"`
+}
+
+// Форматы значения уровня
+const (
+	LevelFormatLower = "lower"
+	LevelFormatUpper = "upper"
+	LevelFormatGCP   = "gcp"
+)
+
+// gcpSeverity соответствие уровней zerolog severity Google Cloud Logging
+var gcpSeverity = map[zerolog.Level]string{
+	zerolog.TraceLevel: "DEBUG",
+	zerolog.DebugLevel: "DEBUG",
+	zerolog.InfoLevel:  "INFO",
+	zerolog.WarnLevel:  "WARNING",
+	zerolog.ErrorLevel: "ERROR",
+	zerolog.FatalLevel: "CRITICAL",
+	zerolog.PanicLevel: "ALERT",
+}
+
+// levelMarshalFunc возвращает функцию форматирования уровня для format
+func levelMarshalFunc(format string) (func(zerolog.Level) string, error) {
+	switch format {
+	case LevelFormatLower:
+		return func(l zerolog.Level) string { return l.String() }, nil
+	case LevelFormatUpper:
+		return func(l zerolog.Level) string { return strings.ToUpper(l.String()) }, nil
+	case LevelFormatGCP:
+		return func(l zerolog.Level) string {
+			if severity, ok := gcpSeverity[l]; ok {
+				return severity
+			}
+			return "DEFAULT"
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported level format: %s", format)
+	}
 }
 
 // Logger представляет собой обертку над zerolog.Logger
 type Logger struct {
 	logger zerolog.Logger
+	out    io.Writer // исходный writer для Sync
 }
 
 // Event представляет событие логирования
@@ -34,6 +94,14 @@ type Event struct {
 // New создает новый экземпляр логгера
 func New(cfg Config) (*Logger, error) {
 	cfg = sanitize(&cfg)
+	if cfg.Format == FormatGCP {
+		applyGCPFormat(&cfg)
+	}
+
+	levelFunc, err := levelMarshalFunc(cfg.LevelFormat)
+	if err != nil {
+		return nil, err
+	}
 
 	// Настраиваем уровень логирования
 	level, err := zerolog.ParseLevel(cfg.Level)
@@ -47,6 +115,10 @@ func New(cfg Config) (*Logger, error) {
 		cfg.TimeFormat = time.RFC3339
 	}
 	zerolog.TimeFieldFormat = cfg.TimeFormat
+	zerolog.TimestampFieldName = cfg.TimestampFieldName
+	zerolog.LevelFieldName = cfg.LevelFieldName
+	zerolog.MessageFieldName = cfg.MessageFieldName
+	zerolog.LevelFieldMarshalFunc = levelFunc
 
 	// Настраиваем вывод
 	var output io.Writer
@@ -63,27 +135,55 @@ func New(cfg Config) (*Logger, error) {
 		output = file
 	}
 
+	// ConsoleWriter не буферизует, поэтому Sync работает с исходным writer
+	out := output
+
 	// Настраиваем формат вывода
 	if cfg.Format == "console" {
-		output = zerolog.ConsoleWriter{
-			Out:        output,
-			TimeFormat: cfg.TimeFormat,
-		}
+		output = consoleWriter(output, cfg)
 	}
 
 	// Создаем базовый логгер
-	logger := zerolog.New(output).With().Timestamp()
+	logger := zerolog.New(output).With()
+	if !cfg.DisableTimestamp {
+		logger = logger.Timestamp()
+	}
 
 	// Добавляем информацию о вызывающем коде, если требуется
-	if cfg.CallerInfo {
+	if cfg.CallerInfo && cfg.Format != FormatGCP {
 		logger = logger.Caller()
 	}
 
+	result := logger.Logger()
+	if cfg.CallerInfo && cfg.Format == FormatGCP {
+		result = result.Hook(sourceLocationHook{})
+	}
+
 	return &Logger{
-		logger: logger.Logger(),
+		logger: result,
+		out:    out,
 	}, nil
 }
 
+// consoleWriter создает ConsoleWriter с цветами только для терминала
+func consoleWriter(out io.Writer, cfg Config) zerolog.ConsoleWriter {
+	return zerolog.ConsoleWriter{
+		Out:        out,
+		TimeFormat: cfg.TimeFormat,
+		NoColor:    cfg.NoColor || !isTerminal(out),
+		PartsOrder: cfg.PartsOrder,
+	}
+}
+
+// isTerminal сообщает, является ли w терминалом
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
 // SetGlobal устанавливает глобальный логгер
 func SetGlobal(l *Logger) {
 	global = l
@@ -153,6 +253,14 @@ func (l *Logger) GetLevel() zerolog.Level {
 	return l.logger.GetLevel()
 }
 
+// Enabled сообщает, будет ли записано событие с уровнем level с учетом
+// уровня логгера и глобального уровня zerolog. Используйте для пропуска
+// дорогих вычислений полей: события уровня ниже текущего никогда не равны nil,
+// но и не записываются.
+func (l *Logger) Enabled(level zerolog.Level) bool {
+	return level >= l.logger.GetLevel() && level >= zerolog.GlobalLevel() && level != zerolog.Disabled
+}
+
 // Printf-style Methods для Logger
 
 // Debugf логирует форматированное сообщение с уровнем Debug
@@ -189,31 +297,73 @@ func (l *Logger) Panicf(format string, v ...any) {
 
 // With возвращает новый логгер с добавленными полями
 func (l *Logger) With() *Context {
-	return &Context{ctx: l.logger.With()}
+	return &Context{ctx: l.logger.With(), out: l.out}
 }
 
 // WithContext создает новый логгер с контекстом
 func (l *Logger) WithContext(ctx context.Context) *Logger {
-	return &Logger{logger: l.logger.With().Ctx(ctx).Logger()}
+	return &Logger{logger: l.logger.With().Ctx(ctx).Logger(), out: l.out}
 }
 
-// WithFields создает новый логгер с несколькими полями
+// WithFields создает новый логгер с несколькими полями.
+// Каждый вызов копирует буфер контекста родительского логгера и сортирует
+// ключи map, поэтому на горячем пути лучше один раз создать дочерний логгер
+// или использовать WithFieldsPreallocated.
 func (l *Logger) WithFields(fields map[string]any) *Logger {
-	ctx := l.logger.With()
-	for k, v := range fields {
-		ctx = ctx.Interface(k, v)
-	}
-	return &Logger{logger: ctx.Logger()}
+	return &Logger{logger: l.logger.With().Fields(fields).Logger(), out: l.out}
+}
+
+// WithFieldsPreallocated создает новый логгер с полями из заранее
+// подготовленного среза пар ключ-значение ("key1", v1, "key2", v2, ...).
+// В отличие от WithFields не создает map и не сортирует ключи: поля
+// записываются в исходном порядке за один проход. Срез можно
+// переиспользовать между вызовами.
+func (l *Logger) WithFieldsPreallocated(fields []any) *Logger {
+	return &Logger{logger: l.logger.With().Fields(fields).Logger(), out: l.out}
 }
 
 // WithField создает новый логгер с одним полем
 func (l *Logger) WithField(key string, value any) *Logger {
-	return &Logger{logger: l.logger.With().Interface(key, value).Logger()}
+	return &Logger{logger: l.logger.With().Interface(key, value).Logger(), out: l.out}
 }
 
 // WithError создает новый логгер с полем error
 func (l *Logger) WithError(err error) *Logger {
-	return &Logger{logger: l.logger.With().Err(err).Logger()}
+	return &Logger{logger: l.logger.With().Err(err).Logger(), out: l.out}
+}
+
+// Output создает копию логгера, пишущую в w. Поля контекста, уровень и hooks
+// сохраняются, исходный логгер не изменяется.
+func (l *Logger) Output(w io.Writer) *Logger {
+	return &Logger{logger: l.logger.Output(w), out: w}
+}
+
+// syncer реализуют writer с буфером на стороне ОС, например *os.File
+type syncer interface {
+	Sync() error
+}
+
+// flusher реализуют буферизованные и асинхронные writer
+type flusher interface {
+	Flush() error
+}
+
+// Sync сбрасывает буфер writer логгера, если он реализует Sync() error или
+// Flush() error, иначе ничего не делает. Вызывайте перед завершением
+// процесса, например перед os.Exit, чтобы не потерять последние записи.
+func (l *Logger) Sync() error {
+	// stdout и stderr не буферизуются, а Sync для терминала или pipe
+	// возвращает ошибку
+	if l.out == os.Stdout || l.out == os.Stderr {
+		return nil
+	}
+	switch w := l.out.(type) {
+	case syncer:
+		return w.Sync()
+	case flusher:
+		return w.Flush()
+	}
+	return nil
 }
 
 // Raw возвращает базовый zerolog.Logger для расширенного использования
@@ -224,6 +374,7 @@ func (l *Logger) Raw() zerolog.Logger {
 // Context представляет контекст для создания логгера с полями
 type Context struct {
 	ctx zerolog.Context
+	out io.Writer
 }
 
 // Str добавляет строковое поле
@@ -244,6 +395,30 @@ func (c *Context) Int64(key string, val int64) *Context {
 	return c
 }
 
+// Int32 добавляет поле типа int32
+func (c *Context) Int32(key string, val int32) *Context {
+	c.ctx = c.ctx.Int32(key, val)
+	return c
+}
+
+// Uint добавляет поле типа uint
+func (c *Context) Uint(key string, val uint) *Context {
+	c.ctx = c.ctx.Uint(key, val)
+	return c
+}
+
+// Uint64 добавляет поле типа uint64
+func (c *Context) Uint64(key string, val uint64) *Context {
+	c.ctx = c.ctx.Uint64(key, val)
+	return c
+}
+
+// Float32 добавляет поле типа float32
+func (c *Context) Float32(key string, val float32) *Context {
+	c.ctx = c.ctx.Float32(key, val)
+	return c
+}
+
 // Float64 добавляет поле типа float64
 func (c *Context) Float64(key string, val float64) *Context {
 	c.ctx = c.ctx.Float64(key, val)
@@ -280,9 +455,53 @@ func (c *Context) Err(err error) *Context {
 	return c
 }
 
+// Strs добавляет массив строк
+func (c *Context) Strs(key string, vals []string) *Context {
+	c.ctx = c.ctx.Strs(key, vals)
+	return c
+}
+
+// Ints добавляет массив целых чисел
+func (c *Context) Ints(key string, vals []int) *Context {
+	c.ctx = c.ctx.Ints(key, vals)
+	return c
+}
+
+// Errs добавляет массив ошибок
+func (c *Context) Errs(key string, errs []error) *Context {
+	c.ctx = c.ctx.Errs(key, errs)
+	return c
+}
+
+// Bytes добавляет поле из байтов, записанных как строка
+func (c *Context) Bytes(key string, val []byte) *Context {
+	c.ctx = c.ctx.Bytes(key, val)
+	return c
+}
+
+// RawJSON добавляет уже сериализованный JSON без повторного кодирования
+func (c *Context) RawJSON(key string, b []byte) *Context {
+	c.ctx = c.ctx.RawJSON(key, b)
+	return c
+}
+
+// Hex добавляет поле из байтов в шестнадцатеричном виде
+func (c *Context) Hex(key string, val []byte) *Context {
+	c.ctx = c.ctx.Hex(key, val)
+	return c
+}
+
+// Dict добавляет вложенный объект key, поля которого заполняет fn
+func (c *Context) Dict(key string, fn func(*Event)) *Context {
+	dict := zerolog.Dict()
+	fn(&Event{event: dict})
+	c.ctx = c.ctx.Dict(key, dict)
+	return c
+}
+
 // Logger создает логгер с накопленными полями
 func (c *Context) Logger() *Logger {
-	return &Logger{logger: c.ctx.Logger()}
+	return &Logger{logger: c.ctx.Logger(), out: c.out}
 }
 
 // Event Methods
@@ -332,6 +551,38 @@ func (e *Event) Int64(key string, val int64) *Event {
 	return e
 }
 
+// Int32 добавляет поле типа int32 к событию
+func (e *Event) Int32(key string, val int32) *Event {
+	if e.event != nil {
+		e.event.Int32(key, val)
+	}
+	return e
+}
+
+// Uint добавляет поле типа uint к событию
+func (e *Event) Uint(key string, val uint) *Event {
+	if e.event != nil {
+		e.event.Uint(key, val)
+	}
+	return e
+}
+
+// Uint64 добавляет поле типа uint64 к событию
+func (e *Event) Uint64(key string, val uint64) *Event {
+	if e.event != nil {
+		e.event.Uint64(key, val)
+	}
+	return e
+}
+
+// Float32 добавляет поле типа float32 к событию
+func (e *Event) Float32(key string, val float32) *Event {
+	if e.event != nil {
+		e.event.Float32(key, val)
+	}
+	return e
+}
+
 // Float64 добавляет поле типа float64 к событию
 func (e *Event) Float64(key string, val float64) *Event {
 	if e.event != nil {
@@ -380,6 +631,66 @@ func (e *Event) Err(err error) *Event {
 	return e
 }
 
+// Strs добавляет массив строк к событию
+func (e *Event) Strs(key string, vals []string) *Event {
+	if e.event != nil {
+		e.event.Strs(key, vals)
+	}
+	return e
+}
+
+// Ints добавляет массив целых чисел к событию
+func (e *Event) Ints(key string, vals []int) *Event {
+	if e.event != nil {
+		e.event.Ints(key, vals)
+	}
+	return e
+}
+
+// Errs добавляет массив ошибок к событию
+func (e *Event) Errs(key string, errs []error) *Event {
+	if e.event != nil {
+		e.event.Errs(key, errs)
+	}
+	return e
+}
+
+// Bytes добавляет к событию поле из байтов, записанных как строка
+func (e *Event) Bytes(key string, val []byte) *Event {
+	if e.event != nil {
+		e.event.Bytes(key, val)
+	}
+	return e
+}
+
+// RawJSON добавляет к событию уже сериализованный JSON без повторного
+// кодирования. Байты не проверяются: невалидный JSON испортит всю запись.
+func (e *Event) RawJSON(key string, b []byte) *Event {
+	if e.event != nil {
+		e.event.RawJSON(key, b)
+	}
+	return e
+}
+
+// Hex добавляет к событию поле из байтов в шестнадцатеричном виде
+func (e *Event) Hex(key string, val []byte) *Event {
+	if e.event != nil {
+		e.event.Hex(key, val)
+	}
+	return e
+}
+
+// Dict добавляет к событию вложенный объект key, поля которого заполняет fn.
+// Для отключенного уровня fn не вызывается.
+func (e *Event) Dict(key string, fn func(*Event)) *Event {
+	if e.event != nil {
+		dict := zerolog.Dict()
+		fn(&Event{event: dict})
+		e.event.Dict(key, dict)
+	}
+	return e
+}
+
 // Global Functions - удобные функции для использования глобального логгера
 
 // Debug создает событие Debug с глобальным логгером
@@ -478,6 +789,11 @@ func WithContext(ctx context.Context) *Logger {
 
 // Utility Functions
 
+// Sync сбрасывает буфер writer глобального логгера
+func Sync() error {
+	return GetGlobal().Sync()
+}
+
 // SetLevel устанавливает глобальный уровень логирования
 func SetLevel(level string) error {
 	lvl, err := zerolog.ParseLevel(level)
@@ -493,6 +809,11 @@ func GetLevel() string {
 	return GetGlobal().GetLevel().String()
 }
 
+// Enabled сообщает, будет ли записано событие с уровнем level глобальным логгером
+func Enabled(level zerolog.Level) bool {
+	return GetGlobal().Enabled(level)
+}
+
 // sanitize ensures the Config struct is populated with default values when fields are empty.
 func sanitize(cfg *Config) Config {
 	if cfg.Level == "" {
@@ -507,5 +828,17 @@ func sanitize(cfg *Config) Config {
 	if cfg.TimeFormat == "" {
 		cfg.TimeFormat = time.RFC3339
 	}
+	if cfg.TimestampFieldName == "" {
+		cfg.TimestampFieldName = "time"
+	}
+	if cfg.LevelFieldName == "" {
+		cfg.LevelFieldName = "level"
+	}
+	if cfg.MessageFieldName == "" {
+		cfg.MessageFieldName = "message"
+	}
+	if cfg.LevelFormat == "" {
+		cfg.LevelFormat = LevelFormatLower
+	}
 	return *cfg
 }
@@ -2,6 +2,10 @@ package logger
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -62,6 +66,235 @@ func TestLoggerWithFields(t *testing.T) {
 	}
 }
 
+func TestLoggerWithFieldsPreallocated(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{
+		logger: zerolog.New(&buf),
+	}
+
+	l.WithFieldsPreallocated([]any{"key1", "value1", "key2", 42}).Info().Msg("test message")
+
+	output := buf.String()
+	if !strings.Contains(output, `"key1":"value1","key2":42`) {
+		t.Errorf("fields not found in output: %s", output)
+	}
+}
+
+func BenchmarkWithFields(b *testing.B) {
+	l := &Logger{logger: zerolog.New(io.Discard)}
+	fields := map[string]any{
+		"user_id":    "42",
+		"request_id": "abc",
+		"attempt":    3,
+		"cached":     true,
+	}
+
+	b.Run("interface", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			// Прежняя реализация: отдельный Interface для каждого поля
+			ctx := l.logger.With()
+			for k, v := range fields {
+				ctx = ctx.Interface(k, v)
+			}
+			_ = ctx.Logger()
+		}
+	})
+
+	b.Run("map", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = l.WithFields(fields)
+		}
+	})
+
+	b.Run("preallocated", func(b *testing.B) {
+		batch := []any{"user_id", "42", "request_id", "abc", "attempt", 3, "cached", true}
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = l.WithFieldsPreallocated(batch)
+		}
+	})
+}
+
+func TestLoggerOutput(t *testing.T) {
+	var original, captured bytes.Buffer
+	l := (&Logger{logger: zerolog.New(&original).Level(zerolog.WarnLevel)}).WithField("component", "billing")
+
+	redirected := l.Output(&captured)
+	redirected.Info().Msg("filtered")
+	redirected.Warn().Msg("captured")
+
+	output := captured.String()
+	if !strings.Contains(output, `"component":"billing"`) || !strings.Contains(output, "captured") {
+		t.Errorf("context fields not preserved in redirected output: %s", output)
+	}
+	if strings.Contains(output, "filtered") {
+		t.Errorf("level not preserved in redirected output: %s", output)
+	}
+	if original.Len() != 0 {
+		t.Errorf("redirected logger wrote to the original writer: %s", original.String())
+	}
+
+	l.Warn().Msg("original")
+	if !strings.Contains(original.String(), "original") || strings.Contains(captured.String(), `"message":"original"`) {
+		t.Error("original logger was modified by Output")
+	}
+}
+
+type flushingWriter struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (w *flushingWriter) Flush() error {
+	w.flushes++
+	return nil
+}
+
+func TestLoggerSync(t *testing.T) {
+	var w flushingWriter
+	l := (&Logger{logger: zerolog.New(io.Discard)}).Output(&w).With().Str("component", "billing").Logger()
+	if err := l.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if w.flushes != 1 {
+		t.Errorf("flushes = %d, want 1", w.flushes)
+	}
+
+	if err := (&Logger{logger: zerolog.New(io.Discard), out: io.Discard}).Sync(); err != nil {
+		t.Errorf("Sync() without Flush/Sync error = %v", err)
+	}
+
+	l, err := New(Config{Output: filepath.Join(t.TempDir(), "app.log")})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	l.Info().Msg("written")
+	if err := l.WithField("request_id", "1").Sync(); err != nil {
+		t.Errorf("Sync() of file output error = %v", err)
+	}
+}
+
+func TestLoggerEnabled(t *testing.T) {
+	prev := zerolog.GlobalLevel()
+	defer zerolog.SetGlobalLevel(prev)
+	zerolog.SetGlobalLevel(zerolog.TraceLevel)
+
+	l := &Logger{logger: zerolog.New(io.Discard).Level(zerolog.InfoLevel)}
+
+	if l.Enabled(zerolog.DebugLevel) {
+		t.Error("debug should be disabled at info level")
+	}
+	if !l.Enabled(zerolog.WarnLevel) {
+		t.Error("warn should be enabled at info level")
+	}
+
+	zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	if l.Enabled(zerolog.WarnLevel) {
+		t.Error("warn should be disabled by the global level")
+	}
+}
+
+func TestEventDict(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{logger: zerolog.New(&buf)}
+
+	l.With().Dict("service", func(e *Event) {
+		e.Str("name", "api")
+	}).Logger().Info().Dict("http", func(e *Event) {
+		e.Str("method", "GET").Int("status", 200)
+	}).Msg("request")
+
+	output := buf.String()
+	if !strings.Contains(output, `"service":{"name":"api"}`) {
+		t.Errorf("context dict not found in output: %s", output)
+	}
+	if !strings.Contains(output, `"http":{"method":"GET","status":200}`) {
+		t.Errorf("event dict not found in output: %s", output)
+	}
+}
+
+func TestEventArrays(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{logger: zerolog.New(&buf)}
+
+	l.Info().
+		Strs("ids", []string{"a", "b"}).
+		Ints("counts", []int{1, 2}).
+		Hex("hash", []byte{0xde, 0xad}).
+		RawJSON("payload", []byte(`{"id": 1}`)).
+		Msg("audit")
+
+	output := buf.String()
+	for _, want := range []string{`"ids":["a","b"]`, `"counts":[1,2]`, `"hash":"dead"`, `"payload":{"id": 1}`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("%s not found in output: %s", want, output)
+		}
+	}
+}
+
+func TestEventNilArrays(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{logger: zerolog.New(&buf)}
+
+	l.With().Strs("tags", nil).Logger().Info().
+		Strs("ids", nil).
+		Ints("counts", nil).
+		Errs("errors", nil).
+		Msg("empty")
+
+	output := buf.String()
+	for _, want := range []string{`"tags":[]`, `"ids":[]`, `"counts":[]`, `"errors":[]`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("%s not found in output: %s", want, output)
+		}
+	}
+}
+
+func TestEventNumericTypes(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{logger: zerolog.New(&buf)}
+
+	l.With().
+		Uint64("tenant_id", 18446744073709551615).
+		RawJSON("meta", []byte(`{"v":2}`)).
+		Logger().Info().
+		Int32("shard", -7).
+		Uint("retries", 3).
+		Uint64("order_id", 9007199254740993).
+		Float32("ratio", 0.5).
+		Bytes("body", []byte("ok")).
+		Msg("typed")
+
+	var fields map[string]any
+	decoder := json.NewDecoder(&buf)
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+
+	want := map[string]string{
+		"tenant_id": "18446744073709551615",
+		"shard":     "-7",
+		"retries":   "3",
+		"order_id":  "9007199254740993",
+		"ratio":     "0.5",
+	}
+	for key, value := range want {
+		number, ok := fields[key].(json.Number)
+		if !ok || number.String() != value {
+			t.Errorf("%s = %#v, want JSON number %s", key, fields[key], value)
+		}
+	}
+	if fields["body"] != "ok" {
+		t.Errorf("body = %#v, want string", fields["body"])
+	}
+	if meta, ok := fields["meta"].(map[string]any); !ok || meta["v"] != json.Number("2") {
+		t.Errorf("meta = %#v, want JSON object", fields["meta"])
+	}
+}
+
 func TestGlobalFunctions(t *testing.T) {
 	// Test that global functions don't panic
 	Debug().Msg("global debug")
@@ -113,6 +346,143 @@ func TestSanitizeConfig(t *testing.T) {
 	}
 }
 
+func TestConsoleWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer := consoleWriter(&buf, Config{PartsOrder: []string{"message", "level"}})
+	if !writer.NoColor {
+		t.Error("colors enabled for non-terminal output")
+	}
+
+	prev := zerolog.GlobalLevel()
+	defer zerolog.SetGlobalLevel(prev)
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+
+	zl := zerolog.New(writer)
+	zl.Info().Str("user", "alice").Msg("login")
+	output := buf.String()
+	if strings.Contains(output, "\x1b[") {
+		t.Errorf("output contains color codes: %q", output)
+	}
+	if !strings.HasPrefix(output, "login INF") || !strings.Contains(output, "user=alice") {
+		t.Errorf("output = %q, want message before level", output)
+	}
+
+	if w := consoleWriter(os.Stdout, Config{NoColor: true}); !w.NoColor {
+		t.Error("NoColor from config ignored")
+	}
+}
+
+func TestTimestampFieldName(t *testing.T) {
+	defer func() {
+		_, _ = New(Config{})
+	}()
+
+	tests := []struct {
+		name    string
+		config  Config
+		want    string
+		notWant string
+	}{
+		{
+			name:    "custom field name",
+			config:  Config{TimestampFieldName: "@timestamp"},
+			want:    `"@timestamp":`,
+			notWant: `"time":`,
+		},
+		{
+			name:    "disabled timestamp",
+			config:  Config{DisableTimestamp: true},
+			notWant: `"time":`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			tt.config.Output = path
+			l, err := New(tt.config)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			l.Info().Msg("test message")
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read log: %v", err)
+			}
+			output := string(data)
+			if tt.want != "" && !strings.Contains(output, tt.want) {
+				t.Errorf("%s not found in output: %s", tt.want, output)
+			}
+			if strings.Contains(output, tt.notWant) {
+				t.Errorf("unexpected %s in output: %s", tt.notWant, output)
+			}
+		})
+	}
+}
+
+func TestLevelAndMessageFieldNames(t *testing.T) {
+	defer func() {
+		_, _ = New(Config{})
+	}()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(Config{
+		Output:           path,
+		LevelFieldName:   "severity",
+		MessageFieldName: "msg",
+		LevelFormat:      LevelFormatGCP,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	l.Warn().Msg("disk almost full")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	output := string(data)
+	for _, want := range []string{`"severity":"WARNING"`, `"msg":"disk almost full"`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("%s not found in output: %s", want, output)
+		}
+	}
+
+	if _, err := New(Config{LevelFormat: "numeric"}); err == nil {
+		t.Error("expected error for unsupported level format")
+	}
+}
+
+func TestGCPFormat(t *testing.T) {
+	defer func() {
+		_, _ = New(Config{})
+	}()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(Config{Output: path, Format: FormatGCP, CallerInfo: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	l.Error().Msg("payment failed")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	output := string(data)
+	for _, want := range []string{
+		`"severity":"ERROR"`,
+		`"message":"payment failed"`,
+		`"` + GCPSourceLocationField + `":{"file":`,
+		`logger_test.go`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("%s not found in output: %s", want, output)
+		}
+	}
+}
+
 func TestInit(t *testing.T) {
 	cfg := Config{
 		Level:  "debug",
@@ -6,6 +6,7 @@ require (
 	github.com/gofiber/fiber/v2 v2.52.8
 	github.com/prometheus/client_golang v1.22.0
 	gitlab.com/zynero/shared/logger v0.1.20
+	gitlab.com/zynero/shared/observability v0.1.21
 )
 
 require (
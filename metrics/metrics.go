@@ -1,48 +1,87 @@
 package metrics
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
 	platformlogger "gitlab.com/zynero/shared/logger"
+	"gitlab.com/zynero/shared/observability"
 )
 
 // Config представляет конфигурацию метрик
 type Config struct {
-	Enabled     bool   `mapstructure:"enabled"`
+	Enabled bool `mapstructure:"enabled"`
+	// Path путь эндпоинта метрик, по умолчанию DefaultPath
 	Path        string `mapstructure:"path"`
 	Port        int    `mapstructure:"port"`
 	ServiceName string `mapstructure:"service_name"`
+
+	// Настройки Pushgateway для короткоживущих задач
+	PushGatewayURL     string            `mapstructure:"push_gateway_url"`
+	PushJobName        string            `mapstructure:"push_job_name"` // по умолчанию ServiceName
+	PushGroupingLabels map[string]string `mapstructure:"push_grouping_labels"`
+	PushInterval       time.Duration     `mapstructure:"push_interval"` // 0 отключает автоматическую отправку
+
+	// PathNormalizer возвращает шаблон маршрута для метки path в HTTPMiddleware.
+	// Вызывается после обработки запроса. Если не задан, используется
+	// r.Pattern, заполняемый http.ServeMux. Пустой результат заменяется на "unknown".
+	PathNormalizer func(r *http.Request) string `mapstructure:"-"`
 }
 
+// DefaultPath путь эндпоинта метрик по умолчанию
+const DefaultPath = "/metrics"
+
+// unknownPath значение метки path для запросов без сопоставленного маршрута
+const unknownPath = "unknown"
+
+// finalPushTimeout ограничивает последнюю отправку метрик при остановке
+const finalPushTimeout = 5 * time.Second
+
 // Metrics представляет собой менеджер метрик
 type Metrics struct {
-	config Config
-	server *http.Server
+	config   Config
+	server   *observability.Server
+	registry *prometheus.Registry
+	gatherer prometheus.Gatherer
+
+	pushStop chan struct{}
+	pushDone chan struct{}
 
 	// HTTP метрики
 	httpRequestsTotal    *prometheus.CounterVec
 	httpRequestDuration  *prometheus.HistogramVec
 	httpRequestsInFlight *prometheus.GaugeVec
+	panicsTotal          *prometheus.CounterVec
 }
 
 // New создает и запускает новый экземпляр менеджера метрик
 func New(cfg Config) (*Metrics, error) {
-	if !cfg.Enabled {
-		return &Metrics{config: cfg}, nil
+	m := &Metrics{
+		config:   cfg,
+		registry: prometheus.NewRegistry(),
 	}
 
-	m := &Metrics{
-		config: cfg,
+	if !cfg.Enabled {
+		return m, nil
 	}
+	if cfg.Path == "" {
+		cfg.Path = DefaultPath
+		m.config.Path = DefaultPath
+	}
+
+	factory := promauto.With(m.registry)
 
 	// Инициализация HTTP метрик
-	m.httpRequestsTotal = promauto.NewCounterVec(
+	m.httpRequestsTotal = factory.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: fmt.Sprintf("%s_http_requests_total", cfg.ServiceName),
 			Help: "Total number of HTTP requests",
@@ -50,7 +89,7 @@ func New(cfg Config) (*Metrics, error) {
 		[]string{"method", "path", "status"},
 	)
 
-	m.httpRequestDuration = promauto.NewHistogramVec(
+	m.httpRequestDuration = factory.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    fmt.Sprintf("%s_http_request_duration_seconds", cfg.ServiceName),
 			Help:    "HTTP request duration in seconds",
@@ -59,39 +98,196 @@ func New(cfg Config) (*Metrics, error) {
 		[]string{"method", "path"},
 	)
 
-	m.httpRequestsInFlight = promauto.NewGaugeVec(
+	m.httpRequestsInFlight = factory.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: fmt.Sprintf("%s_http_requests_in_flight", cfg.ServiceName),
 			Help: "Current number of HTTP requests being served",
 		},
-		[]string{"method", "path"},
+		// маршрут ещё не известен в момент начала запроса, поэтому только method
+		[]string{"method"},
 	)
 
-	// Запускаем HTTP-сервер для метрик
-	mux := http.NewServeMux()
-	mux.Handle(cfg.Path, promhttp.Handler())
+	m.panicsTotal = factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_panics_total", cfg.ServiceName),
+			Help: "Total number of panics recovered in HTTP handlers",
+		},
+		[]string{"path"},
+	)
 
-	m.server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.Port),
-		Handler: mux,
+	// Запускаем HTTP-сервер для метрик. Отдаем как собственный реестр,
+	// так и глобальный, в котором регистрируются сторонние библиотеки.
+	// Сервер разделяется с health-check, если они настроены на один порт.
+	m.gatherer = prometheus.Gatherers{m.registry, prometheus.DefaultGatherer}
+	server, err := observability.Acquire(cfg.Port)
+	if err != nil {
+		return nil, err
 	}
+	if err := server.Handle(cfg.Path, promhttp.HandlerFor(m.gatherer, promhttp.HandlerOpts{})); err != nil {
+		_ = server.Close()
+		return nil, err
+	}
+	m.server = server
+	platformlogger.Info().Msgf("Serving metrics on %s%s", server.Addr(), cfg.Path)
 
-	go func() {
-		platformlogger.Info().Msgf("Starting metrics server on %s", m.server.Addr)
-		if err := m.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			panic(fmt.Sprintf("Failed to start metrics server: %v", err))
-		}
-	}()
+	// Запускаем периодическую отправку в Pushgateway
+	if cfg.PushGatewayURL != "" && cfg.PushInterval > 0 {
+		m.pushStop = make(chan struct{})
+		m.pushDone = make(chan struct{})
+		go m.pushLoop()
+	}
 
 	return m, nil
 }
 
-// Stop останавливает HTTP-сервер метрик
+// Errors возвращает канал ошибок HTTP-сервера, возникших после запуска.
+// Для отключенного компонента возвращает nil-канал.
+func (m *Metrics) Errors() <-chan error {
+	if m.server == nil {
+		return nil
+	}
+	return m.server.Errors()
+}
+
+// Stop останавливает HTTP-сервер метрик. При включенной автоматической
+// отправке выполняет последний push, чтобы не потерять метрики задачи.
 func (m *Metrics) Stop() error {
-	if !m.config.Enabled || m.server == nil {
+	if !m.config.Enabled {
 		return nil
 	}
-	return m.server.Close()
+
+	var errs []error
+	if m.pushStop != nil {
+		close(m.pushStop)
+		<-m.pushDone
+		m.pushStop = nil
+
+		ctx, cancel := context.WithTimeout(context.Background(), finalPushTimeout)
+		defer cancel()
+		if err := m.Push(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if m.server != nil {
+		if err := m.server.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		m.server = nil
+	}
+	return errors.Join(errs...)
+}
+
+// Push отправляет текущие метрики в Pushgateway, заменяя ранее отправленные
+// метрики той же задачи и группы
+func (m *Metrics) Push(ctx context.Context) error {
+	if !m.config.Enabled {
+		return nil
+	}
+	if m.config.PushGatewayURL == "" {
+		return errors.New("push gateway url is not configured")
+	}
+
+	job := m.config.PushJobName
+	if job == "" {
+		job = m.config.ServiceName
+	}
+
+	pusher := push.New(m.config.PushGatewayURL, job).Gatherer(m.gatherer)
+	for name, value := range m.config.PushGroupingLabels {
+		pusher = pusher.Grouping(name, value)
+	}
+
+	if err := pusher.PushContext(ctx); err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	return nil
+}
+
+// pushLoop периодически отправляет метрики в Pushgateway до вызова Stop
+func (m *Metrics) pushLoop() {
+	defer close(m.pushDone)
+
+	ticker := time.NewTicker(m.config.PushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), m.config.PushInterval)
+			if err := m.Push(ctx); err != nil {
+				platformlogger.Error().Err(err).Msg("Failed to push metrics")
+			}
+			cancel()
+		case <-m.pushStop:
+			return
+		}
+	}
+}
+
+// Registry возвращает реестр, метрики которого отдаются эндпоинтом метрик
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// MustRegister регистрирует пользовательские коллекторы в реестре метрик.
+// Паникует, если коллектор уже зарегистрирован.
+func (m *Metrics) MustRegister(cs ...prometheus.Collector) {
+	m.registry.MustRegister(cs...)
+}
+
+// NewCounter создает и регистрирует счетчик с именем, дополненным префиксом сервиса
+func (m *Metrics) NewCounter(name, help string, labels ...string) (*prometheus.CounterVec, error) {
+	fqName := m.metricName(name)
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: fqName, Help: help}, labels)
+	if err := m.register(fqName, vec); err != nil {
+		return nil, err
+	}
+	return vec, nil
+}
+
+// NewGauge создает и регистрирует gauge с именем, дополненным префиксом сервиса
+func (m *Metrics) NewGauge(name, help string, labels ...string) (*prometheus.GaugeVec, error) {
+	fqName := m.metricName(name)
+	vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: fqName, Help: help}, labels)
+	if err := m.register(fqName, vec); err != nil {
+		return nil, err
+	}
+	return vec, nil
+}
+
+// NewHistogram создает и регистрирует гистограмму с именем, дополненным
+// префиксом сервиса. Если buckets не заданы, используются prometheus.DefBuckets.
+func (m *Metrics) NewHistogram(name, help string, buckets []float64, labels ...string) (*prometheus.HistogramVec, error) {
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+	fqName := m.metricName(name)
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: fqName, Help: help, Buckets: buckets}, labels)
+	if err := m.register(fqName, vec); err != nil {
+		return nil, err
+	}
+	return vec, nil
+}
+
+// metricName добавляет к имени метрики префикс сервиса
+func (m *Metrics) metricName(name string) string {
+	if m.config.ServiceName == "" {
+		return name
+	}
+	return fmt.Sprintf("%s_%s", m.config.ServiceName, name)
+}
+
+// register регистрирует коллектор в реестре, возвращая понятную ошибку при повторной регистрации
+func (m *Metrics) register(fqName string, c prometheus.Collector) error {
+	if err := m.registry.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			return fmt.Errorf("metric %s is already registered", fqName)
+		}
+		return fmt.Errorf("failed to register metric %s: %w", fqName, err)
+	}
+	return nil
 }
 
 // HTTPMiddleware возвращает middleware для сбора HTTP метрик
@@ -104,8 +300,8 @@ func (m *Metrics) HTTPMiddleware(next http.Handler) http.Handler {
 		start := time.Now()
 
 		// Увеличиваем счетчик текущих запросов
-		m.httpRequestsInFlight.WithLabelValues(r.Method, r.URL.Path).Inc()
-		defer m.httpRequestsInFlight.WithLabelValues(r.Method, r.URL.Path).Dec()
+		m.httpRequestsInFlight.WithLabelValues(r.Method).Inc()
+		defer m.httpRequestsInFlight.WithLabelValues(r.Method).Dec()
 
 		// Создаем ResponseWriter для перехвата статуса
 		rw := &responseWriter{ResponseWriter: w}
@@ -114,9 +310,10 @@ func (m *Metrics) HTTPMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(rw, r)
 
 		// Записываем метрики
+		path := m.httpRoutePath(r)
 		duration := time.Since(start).Seconds()
-		m.httpRequestDuration.WithLabelValues(r.Method, r.URL.Path).Observe(duration)
-		m.httpRequestsTotal.WithLabelValues(r.Method, r.URL.Path, fmt.Sprintf("%d", rw.status)).Inc()
+		m.httpRequestDuration.WithLabelValues(r.Method, path).Observe(duration)
+		m.httpRequestsTotal.WithLabelValues(r.Method, path, fmt.Sprintf("%d", rw.status)).Inc()
 	})
 }
 
@@ -131,22 +328,100 @@ func (m *Metrics) FiberMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		start := time.Now()
 
+		// Метод копируем, так как fasthttp переиспользует буферы
+		method := strings.Clone(c.Method())
+
 		// Увеличиваем счетчик текущих запросов
-		m.httpRequestsInFlight.WithLabelValues(c.Method(), c.Path()).Inc()
-		defer m.httpRequestsInFlight.WithLabelValues(c.Method(), c.Path()).Dec()
+		m.httpRequestsInFlight.WithLabelValues(method).Inc()
+		defer m.httpRequestsInFlight.WithLabelValues(method).Dec()
 
 		// Вызываем следующий обработчик
 		err := c.Next()
 
 		// Записываем метрики
+		path := fiberRoutePath(c)
 		duration := time.Since(start).Seconds()
-		m.httpRequestDuration.WithLabelValues(c.Method(), c.Path()).Observe(duration)
-		m.httpRequestsTotal.WithLabelValues(c.Method(), c.Path(), fmt.Sprintf("%d", c.Response().StatusCode())).Inc()
+		m.httpRequestDuration.WithLabelValues(method, path).Observe(duration)
+		m.httpRequestsTotal.WithLabelValues(method, path, fmt.Sprintf("%d", c.Response().StatusCode())).Inc()
 
 		return err
 	}
 }
 
+// HTTPRecoveryMiddleware возвращает middleware, считающее паники в обработчиках.
+// После учета и логирования паника пробрасывается дальше, поэтому middleware
+// должно располагаться внутри основного recover-обработчика сервера.
+func (m *Metrics) HTTPRecoveryMiddleware(next http.Handler) http.Handler {
+	if !m.config.Enabled {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				// http.ErrAbortHandler используется для штатного прерывания ответа
+				if rec != http.ErrAbortHandler {
+					m.recordPanic(m.httpRoutePath(r), rec)
+				}
+				panic(rec)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// FiberRecoveryMiddleware возвращает middleware для Fiber, считающее паники.
+// Должно регистрироваться после recover middleware сервера, которое
+// обработает пробрасываемую дальше панику.
+func (m *Metrics) FiberRecoveryMiddleware() fiber.Handler {
+	if !m.config.Enabled {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		defer func() {
+			if rec := recover(); rec != nil {
+				m.recordPanic(fiberRoutePath(c), rec)
+				panic(rec)
+			}
+		}()
+		return c.Next()
+	}
+}
+
+// recordPanic увеличивает счетчик паник и логирует их
+func (m *Metrics) recordPanic(path string, rec any) {
+	m.panicsTotal.WithLabelValues(path).Inc()
+	platformlogger.Error().
+		Str("path", path).
+		Interface("panic", rec).
+		Msg("Panic in HTTP handler")
+}
+
+// httpRoutePath возвращает шаблон маршрута для метки path
+func (m *Metrics) httpRoutePath(r *http.Request) string {
+	path := r.Pattern
+	if m.config.PathNormalizer != nil {
+		path = m.config.PathNormalizer(r)
+	}
+	if path == "" {
+		return unknownPath
+	}
+	return path
+}
+
+// fiberRoutePath возвращает шаблон сопоставленного маршрута Fiber.
+// Если запрос дошел только до middleware (маршрут не найден), возвращает "unknown".
+func fiberRoutePath(c *fiber.Ctx) string {
+	route := c.Route()
+	if route == nil || route.Method == "USE" || route.Path == "" {
+		return unknownPath
+	}
+	return route.Path
+}
+
 // responseWriter перехватывает статус ответа
 type responseWriter struct {
 	http.ResponseWriter
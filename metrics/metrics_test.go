@@ -0,0 +1,315 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	fiberrecover "github.com/gofiber/fiber/v2/middleware/recover"
+	"gitlab.com/zynero/shared/observability"
+)
+
+func TestNewCounterRejectsDuplicate(t *testing.T) {
+	m, err := New(Config{Enabled: false, ServiceName: "svc"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	counter, err := m.NewCounter("orders_total", "Total orders", "status")
+	if err != nil {
+		t.Fatalf("NewCounter() error = %v", err)
+	}
+	counter.WithLabelValues("paid").Inc()
+
+	if _, err := m.NewCounter("orders_total", "Total orders", "status"); err == nil {
+		t.Fatal("NewCounter() with a duplicate name should return an error")
+	}
+
+	families, err := m.Registry().Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, f := range families {
+		if f.GetName() == "svc_orders_total" {
+			return
+		}
+	}
+	t.Error("counter should be registered with the service name prefix")
+}
+
+func TestNewTwiceDoesNotPanic(t *testing.T) {
+	cfg := Config{Enabled: true, ServiceName: "svc"}
+
+	first, err := New(cfg)
+	if err != nil {
+		t.Fatalf("first New() error = %v", err)
+	}
+
+	// Второй экземпляр на том же порту и пути не может отдавать метрики
+	if _, err := New(cfg); !errors.Is(err, observability.ErrInvalidPattern) {
+		t.Fatalf("second New() error = %v, want ErrInvalidPattern", err)
+	}
+
+	if err := first.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	second, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() after Stop() error = %v", err)
+	}
+	defer second.Stop()
+
+	if first.Registry() == second.Registry() {
+		t.Error("each Metrics instance should own its registry")
+	}
+}
+
+// pushGateway записывает запросы, пришедшие в Pushgateway
+type pushGateway struct {
+	mu     sync.Mutex
+	paths  []string
+	bodies []string
+}
+
+func (g *pushGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	g.mu.Lock()
+	g.paths = append(g.paths, r.Method+" "+r.URL.Path)
+	g.bodies = append(g.bodies, string(body))
+	g.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (g *pushGateway) count() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.paths)
+}
+
+func TestPush(t *testing.T) {
+	gateway := &pushGateway{}
+	srv := httptest.NewServer(gateway)
+	defer srv.Close()
+
+	m, err := New(Config{
+		Enabled:            true,
+		ServiceName:        "svc",
+		PushGatewayURL:     srv.URL,
+		PushGroupingLabels: map[string]string{"instance": "worker-1"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer m.Stop()
+
+	counter, err := m.NewCounter("jobs_total", "Processed jobs")
+	if err != nil {
+		t.Fatalf("NewCounter() error = %v", err)
+	}
+	counter.WithLabelValues().Inc()
+
+	if err := m.Push(context.Background()); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	// Имя задачи по умолчанию - ServiceName, метки группировки идут в путь
+	if want := "PUT /metrics/job/svc/instance/worker-1"; len(gateway.paths) != 1 || gateway.paths[0] != want {
+		t.Errorf("requests = %v, want [%s]", gateway.paths, want)
+	}
+	if !strings.Contains(gateway.bodies[0], "svc_jobs_total") {
+		t.Error("pushed metrics should include the service registry")
+	}
+}
+
+func TestPushErrors(t *testing.T) {
+	disabled, err := New(Config{Enabled: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := disabled.Push(context.Background()); err != nil {
+		t.Errorf("Push() on disabled metrics error = %v", err)
+	}
+
+	m, err := New(Config{Enabled: true, ServiceName: "svc"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer m.Stop()
+	if err := m.Push(context.Background()); err == nil {
+		t.Error("Push() without PushGatewayURL should return an error")
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	m.config.PushGatewayURL = srv.URL
+	if err := m.Push(context.Background()); err == nil {
+		t.Error("Push() should return an error when the gateway fails")
+	}
+}
+
+func TestPushLoop(t *testing.T) {
+	gateway := &pushGateway{}
+	srv := httptest.NewServer(gateway)
+	defer srv.Close()
+
+	m, err := New(Config{
+		Enabled:        true,
+		ServiceName:    "svc",
+		PushGatewayURL: srv.URL,
+		PushJobName:    "batch",
+		PushInterval:   20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for gateway.count() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("pushes = %d, want periodic pushes", gateway.count())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Stop останавливает цикл и выполняет последнюю отправку
+	before := gateway.count()
+	if err := m.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	after := gateway.count()
+	if after < before+1 {
+		t.Errorf("pushes after Stop = %d, want a final push after %d", after, before)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if gateway.count() != after {
+		t.Errorf("pushes = %d after Stop, want no more than %d", gateway.count(), after)
+	}
+	if gateway.paths[0] != "PUT /metrics/job/batch" {
+		t.Errorf("request = %s, want PushJobName in the path", gateway.paths[0])
+	}
+}
+
+// panicsByPath возвращает значения panics_total по метке path
+func panicsByPath(t *testing.T, m *Metrics) map[string]float64 {
+	t.Helper()
+
+	families, err := m.Registry().Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	panics := map[string]float64{}
+	for _, f := range families {
+		if f.GetName() != "svc_panics_total" {
+			continue
+		}
+		for _, metric := range f.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "path" {
+					panics[label.GetValue()] = metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return panics
+}
+
+func TestHTTPRecoveryMiddleware(t *testing.T) {
+	m, err := New(Config{Enabled: true, ServiceName: "svc"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer m.Stop()
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /orders/{id}", m.HTTPRecoveryMiddleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})))
+	mux.Handle("GET /abort", m.HTTPRecoveryMiddleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	})))
+
+	// Внешний recover сервера получает пробрасываемую панику
+	var recovered []any
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				recovered = append(recovered, rec)
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}()
+		mux.ServeHTTP(w, r)
+	})
+
+	for _, path := range []string{"/orders/1", "/orders/2", "/abort"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if len(recovered) != 3 {
+		t.Errorf("recovered %d panics, want all 3 re-panicked", len(recovered))
+	}
+	// http.ErrAbortHandler не считается паникой
+	if got := panicsByPath(t, m); len(got) != 1 || got["GET /orders/{id}"] != 2 {
+		t.Errorf("panics_total = %v, want 2 for GET /orders/{id}", got)
+	}
+}
+
+func TestFiberRecoveryMiddleware(t *testing.T) {
+	m, err := New(Config{Enabled: true, ServiceName: "svc"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer m.Stop()
+
+	app := fiber.New()
+	app.Use(fiberrecover.New(), m.FiberRecoveryMiddleware())
+	app.Get("/orders/:id", func(c *fiber.Ctx) error {
+		panic("boom")
+	})
+	app.Get("/ok", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	for _, path := range []string{"/orders/1", "/ok"} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("app.Test(%s) error = %v", path, err)
+		}
+		want := fiber.StatusOK
+		if path != "/ok" {
+			want = fiber.StatusInternalServerError
+		}
+		if resp.StatusCode != want {
+			t.Errorf("%s status = %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+
+	if got := panicsByPath(t, m); len(got) != 1 || got["/orders/:id"] != 1 {
+		t.Errorf("panics_total = %v, want 1 for /orders/:id", got)
+	}
+}
+
+func TestRecoveryMiddlewareDisabled(t *testing.T) {
+	m, err := New(Config{Enabled: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Отключенные метрики не создают счетчиков, middleware только передает запрос дальше
+	app := fiber.New()
+	app.Use(m.FiberRecoveryMiddleware())
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	if err != nil || resp.StatusCode != fiber.StatusNoContent {
+		t.Errorf("disabled middleware response = %v, %v, want 204", resp, err)
+	}
+}
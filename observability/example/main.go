@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"gitlab.com/zynero/shared/observability"
+)
+
+func main() {
+	// 1. Порт 0 - система выберет свободный порт
+	server, err := observability.Acquire(0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	// 2. Регистрация служебного эндпоинта
+	err = server.Handle("/info", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "example service\n")
+	}))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// 3. Повторная регистрация того же пути возвращает ошибку
+	if err := server.Handle("/info", http.NotFoundHandler()); err != nil {
+		log.Printf("duplicate handler rejected: %v", err)
+	}
+
+	// 4. Addr возвращает фактический адрес с выбранным портом
+	resp, err := http.Get(fmt.Sprintf("http://%s/info", server.Addr()))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("GET /info on %s: %s", server.Addr(), body)
+}
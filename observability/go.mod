@@ -0,0 +1,12 @@
+module gitlab.com/zynero/shared/observability
+
+go 1.24.2
+
+require gitlab.com/zynero/shared/logger v0.1.20
+
+require (
+	github.com/mattn/go-colorable v0.1.14 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/rs/zerolog v1.34.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+)
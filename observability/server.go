@@ -0,0 +1,147 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	platformlogger "gitlab.com/zynero/shared/logger"
+)
+
+// Server представляет HTTP-сервер служебных эндпоинтов (метрики, health-check).
+// Компоненты, настроенные на один порт, разделяют один сервер и один mux,
+// поэтому порт не занимается дважды.
+type Server struct {
+	port   int
+	mux    *http.ServeMux
+	server *http.Server
+	addr   net.Addr
+	refs   int
+	errCh  chan error
+}
+
+// ErrInvalidPattern означает, что шаблон пути некорректен или конфликтует с
+// уже зарегистрированным на сервере
+var ErrInvalidPattern = errors.New("invalid handler pattern")
+
+var (
+	mu      sync.Mutex
+	servers = make(map[int]*Server)
+)
+
+// Acquire возвращает сервер для указанного порта, создавая и запуская его при
+// первом обращении. Каждый вызов Acquire должен сопровождаться вызовом Close
+// или Shutdown.
+func Acquire(port int) (*Server, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if s, ok := servers[port]; ok {
+		s.refs++
+		return s, nil
+	}
+
+	s := &Server{
+		port:  port,
+		mux:   http.NewServeMux(),
+		refs:  1,
+		errCh: make(chan error, 1),
+	}
+	s.server = &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: s.mux,
+	}
+
+	// Порт занимаем синхронно, чтобы ошибка (например, порт занят) вернулась вызывающему
+	lis, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", s.server.Addr, err)
+	}
+	s.addr = lis.Addr()
+
+	// Глобальный логгер получаем до запуска горутины: его ленивая
+	// инициализация не синхронизирована
+	logger := platformlogger.GetGlobal()
+	logger.Info().Msgf("Starting observability server on %s", s.addr)
+
+	go func() {
+		if err := s.server.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error().Err(err).Msg("Observability server failed")
+			s.errCh <- err
+		}
+		close(s.errCh)
+	}()
+
+	servers[port] = s
+	return s, nil
+}
+
+// Errors возвращает канал, в который передается ошибка сервера, возникшая
+// после запуска. Канал закрывается после остановки сервера.
+func (s *Server) Errors() <-chan error {
+	return s.errCh
+}
+
+// Handle регистрирует обработчик на общем mux сервера. Возвращает
+// ErrInvalidPattern, если шаблон уже зарегистрирован другим компонентом или
+// некорректен.
+func (s *Server) Handle(pattern string, handler http.Handler) (err error) {
+	// http.ServeMux сообщает о конфликте шаблонов паникой
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %q: %v", ErrInvalidPattern, pattern, r)
+		}
+	}()
+	s.mux.Handle(pattern, handler)
+	return nil
+}
+
+// Addr возвращает адрес, который слушает сервер. Для порта 0 это адрес с
+// выбранным системой портом.
+func (s *Server) Addr() string {
+	return s.addr.String()
+}
+
+// Close освобождает сервер. Сервер закрывается, когда его освобождает
+// последний использующий компонент.
+func (s *Server) Close() error {
+	if !s.release() {
+		return nil
+	}
+	return s.server.Close()
+}
+
+// Shutdown освобождает сервер как Close, но последний компонент плавно
+// останавливает его, дожидаясь активных запросов. Если ctx истекает раньше,
+// соединения закрываются принудительно.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if !s.release() {
+		return nil
+	}
+	if err := s.server.Shutdown(ctx); err != nil {
+		if closeErr := s.server.Close(); closeErr != nil {
+			return errors.Join(err, closeErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// release уменьшает счетчик ссылок и сообщает, нужно ли останавливать сервер
+func (s *Server) release() bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if s.refs == 0 {
+		return false
+	}
+	s.refs--
+	if s.refs > 0 {
+		return false
+	}
+	delete(servers, s.port)
+	return true
+}
@@ -0,0 +1,86 @@
+package observability
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAcquireSharesServerPerPort(t *testing.T) {
+	first, err := Acquire(0)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	second, err := Acquire(0)
+	if err != nil {
+		t.Fatalf("second Acquire() error = %v", err)
+	}
+	if first != second {
+		t.Fatal("Acquire() on the same port should return the shared server")
+	}
+
+	if err := first.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if _, ok := servers[0]; !ok {
+		t.Error("server should stay registered while still referenced")
+	}
+	if err := second.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+	if _, ok := servers[0]; ok {
+		t.Error("server should be removed after the last Close")
+	}
+}
+
+func TestAcquireReturnsListenError(t *testing.T) {
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	defer lis.Close()
+
+	port := lis.Addr().(*net.TCPAddr).Port
+	if _, err := Acquire(port); err == nil {
+		t.Fatal("Acquire() on a busy port should return an error")
+	}
+}
+
+func TestHandleRejectsDuplicatePattern(t *testing.T) {
+	s, err := Acquire(0)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer s.Close()
+
+	handler := http.NotFoundHandler()
+	if err := s.Handle("/metrics", handler); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if err := s.Handle("/metrics", handler); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("second Handle() error = %v, want ErrInvalidPattern", err)
+	}
+}
+
+func TestAddrReturnsBoundPort(t *testing.T) {
+	s, err := Acquire(0)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer s.Close()
+
+	addr := s.Addr()
+	if strings.HasSuffix(addr, ":0") {
+		t.Fatalf("Addr() = %q, want the port chosen by the system", addr)
+	}
+	resp, err := http.Get("http://" + addr + "/missing")
+	if err != nil {
+		t.Fatalf("GET %s error = %v", addr, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	platformlogger "gitlab.com/zynero/shared/logger"
+)
+
+// RequestIDHeader заголовок с идентификатором запроса
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID возвращает middleware, назначающее запросу идентификатор.
+// Идентификатор берется из заголовка X-Request-ID или генерируется,
+// сохраняется в Locals для корреляции логов и возвращается в ответе.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(RequestIDHeader)
+		if id == "" {
+			id = uuid.NewString()
+		} else {
+			// Копируем значение, так как fasthttp переиспользует буферы
+			id = string([]byte(id))
+		}
+		c.Locals(platformlogger.RequestIDField, id)
+		c.Set(RequestIDHeader, id)
+		return c.Next()
+	}
+}
+
+// GetRequestID возвращает идентификатор запроса, назначенный RequestID
+func GetRequestID(c *fiber.Ctx) string {
+	id, _ := c.Locals(platformlogger.RequestIDField).(string)
+	return id
+}
+
+// LoggingMiddleware возвращает middleware, логирующее метод, путь, статус и
+// длительность каждого запроса. Если nil, используется глобальный логгер.
+// Ошибка обработчика логируется и возвращается дальше, ответ на нее формирует
+// ErrorHandler приложения.
+func LoggingMiddleware(l *platformlogger.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		logger := l
+		if logger == nil {
+			logger = platformlogger.GetGlobal()
+		}
+
+		event := logger.Info()
+		if err != nil {
+			event = logger.Error().Err(err)
+		}
+		event.
+			Str("method", c.Method()).
+			Str("path", c.Path()).
+			Int("status", responseStatus(c, err)).
+			Dur("latency", time.Since(start))
+		if id := GetRequestID(c); id != "" {
+			event.Str(platformlogger.RequestIDField, id)
+		}
+		event.Msg("http request")
+
+		return err
+	}
+}
+
+// responseStatus возвращает статус ответа. Для ошибки ответ еще не
+// сформирован, поэтому статус берется из *fiber.Error или считается 500.
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
+// Timeout возвращает middleware, ограничивающее время обработки запроса.
+// Контекст обработчика (c.UserContext()) отменяется по истечении d, а если
+// обработчик завершился после дедлайна, клиенту возвращается 503.
+// Обработчики должны учитывать отмену контекста, прервать их принудительно
+// middleware не может.
+func Timeout(d time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), d)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fiber.ErrServiceUnavailable
+		}
+		return err
+	}
+}
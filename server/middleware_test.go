@@ -0,0 +1,176 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	platformlogger "gitlab.com/zynero/shared/logger"
+)
+
+func TestRequestID(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestID())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(GetRequestID(c))
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+	if got := resp.Header.Get(RequestIDHeader); got != "abc-123" {
+		t.Errorf("response %s = %q, want the incoming id", RequestIDHeader, got)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+	body := new(bytes.Buffer)
+	_, _ = body.ReadFrom(resp.Body)
+	generated := resp.Header.Get(RequestIDHeader)
+	if generated == "" || body.String() != generated {
+		t.Errorf("generated id = %q, handler saw %q", generated, body.String())
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    fiber.Handler
+		wantStatus int
+		wantLog    []string
+	}{
+		{
+			name:       "success",
+			handler:    func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusCreated) },
+			wantStatus: fiber.StatusCreated,
+			wantLog:    []string{`"level":"info"`, `"status":201`, `"path":"/orders"`, `"request_id":"req-1"`},
+		},
+		{
+			name:       "fiber error",
+			handler:    func(c *fiber.Ctx) error { return fiber.ErrNotFound },
+			wantStatus: fiber.StatusNotFound,
+			wantLog:    []string{`"level":"error"`, `"status":404`},
+		},
+		{
+			name:       "plain error",
+			handler:    func(c *fiber.Ctx) error { return errors.New("boom") },
+			wantStatus: fiber.StatusTeapot,
+			wantLog:    []string{`"level":"error"`, `"status":500`, `"error":"boom"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := platformlogger.New(platformlogger.Config{Level: "info", Format: "json"})
+			if err != nil {
+				t.Fatalf("logger.New() error = %v", err)
+			}
+			var buf bytes.Buffer
+			l = l.Output(&buf)
+
+			// ErrorHandler приложения должен получить ошибку от middleware
+			app := fiber.New(fiber.Config{ErrorHandler: func(c *fiber.Ctx, err error) error {
+				var fiberErr *fiber.Error
+				if errors.As(err, &fiberErr) {
+					return c.SendStatus(fiberErr.Code)
+				}
+				return c.SendStatus(fiber.StatusTeapot)
+			}})
+			app.Use(RequestID(), LoggingMiddleware(l))
+			app.Get("/orders", tt.handler)
+
+			req := httptest.NewRequest(fiber.MethodGet, "/orders", nil)
+			req.Header.Set(RequestIDHeader, "req-1")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test() error = %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			logged := buf.String()
+			if strings.Count(logged, "http request") != 1 {
+				t.Fatalf("log = %q, want one request entry", logged)
+			}
+			for _, want := range tt.wantLog {
+				if !strings.Contains(logged, want) {
+					t.Errorf("log = %q, want %s", logged, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	handlerErr := errors.New("db unavailable")
+	tests := []struct {
+		name       string
+		handler    fiber.Handler
+		wantStatus int
+	}{
+		{
+			name:       "fast handler",
+			handler:    func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) },
+			wantStatus: fiber.StatusOK,
+		},
+		{
+			name: "handler exceeds deadline",
+			handler: func(c *fiber.Ctx) error {
+				<-c.UserContext().Done()
+				return c.UserContext().Err()
+			},
+			wantStatus: fiber.StatusServiceUnavailable,
+		},
+		{
+			// Ошибка обработчика до истечения таймаута возвращается без изменений
+			name:       "handler error",
+			handler:    func(c *fiber.Ctx) error { return handlerErr },
+			wantStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(Timeout(50 * time.Millisecond))
+			app.Get("/", tt.handler)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil), -1)
+			if err != nil {
+				t.Fatalf("app.Test() error = %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestTimeoutSetsDeadline(t *testing.T) {
+	app := fiber.New()
+	app.Use(Timeout(time.Minute))
+
+	var deadline time.Time
+	var ok bool
+	app.Get("/", func(c *fiber.Ctx) error {
+		deadline, ok = c.UserContext().Deadline()
+		return nil
+	})
+
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil)); err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+	if !ok || time.Until(deadline) <= 0 || time.Until(deadline) > time.Minute {
+		t.Errorf("handler context deadline = %v, %v, want about a minute ahead", deadline, ok)
+	}
+}
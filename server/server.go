@@ -2,9 +2,16 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2/middleware/compress"
+	"github.com/gofiber/fiber/v2/middleware/cors"
 
 	"github.com/bytedance/sonic"
 	"github.com/gofiber/fiber/v2"
@@ -18,22 +25,91 @@ type Config struct {
 	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout     time.Duration `mapstructure:"idle_timeout"`
 	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// BodyLimit ограничивает размер тела запроса в байтах (по умолчанию 4MB)
+	BodyLimit int `mapstructure:"body_limit"`
+	// RequestTimeout ограничивает время обработки запроса middleware Timeout
+	// (по умолчанию без ограничения). ReadTimeout при этом ограничивает только
+	// чтение запроса с сокета, а RequestTimeout - работу обработчика.
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+	// PreShutdownDelay задает паузу между вызовом хуков RegisterOnShutdown и
+	// остановкой сервера, чтобы балансировщик успел перестать слать трафик.
+	// По умолчанию 0 (остановка сразу).
+	PreShutdownDelay time.Duration `mapstructure:"pre_shutdown_delay"`
+
+	// Дополнительные middleware, по умолчанию выключены
+	EnableRequestID      bool `mapstructure:"enable_request_id"`
+	EnableRequestLogging bool `mapstructure:"enable_request_logging"`
+
+	CORS CORSConfig `mapstructure:"cors"`
+
+	// TLS: при заданных CertFile и KeyFile сервер слушает HTTPS
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// TLSConfig позволяет задать расширенные настройки (минимальная версия,
+	// шифры, mTLS). Сертификаты из CertFile/KeyFile добавляются к нему.
+	TLSConfig *tls.Config `mapstructure:"-"`
+}
+
+// CORSConfig представляет настройки CORS. Пустая конфигурация отключает CORS.
+type CORSConfig struct {
+	AllowOrigins     []string      `mapstructure:"allow_origins"`
+	AllowMethods     []string      `mapstructure:"allow_methods"`
+	AllowHeaders     []string      `mapstructure:"allow_headers"`
+	AllowCredentials bool          `mapstructure:"allow_credentials"`
+	MaxAge           time.Duration `mapstructure:"max_age"`
+}
+
+// ErrCORSCredentialsWildcard возвращается при разрешении credentials для любого origin,
+// что запрещено браузерами
+var ErrCORSCredentialsWildcard = errors.New("cors: allow_credentials cannot be used with wildcard origin")
+
+// isZero проверяет, что CORS не настроен
+func (c CORSConfig) isZero() bool {
+	return len(c.AllowOrigins) == 0 && len(c.AllowMethods) == 0 && len(c.AllowHeaders) == 0 &&
+		!c.AllowCredentials && c.MaxAge == 0
+}
+
+// Validate проверяет корректность настроек CORS
+func (c CORSConfig) Validate() error {
+	if c.AllowCredentials && (len(c.AllowOrigins) == 0 || slices.Contains(c.AllowOrigins, "*")) {
+		return ErrCORSCredentialsWildcard
+	}
+	return nil
+}
+
+// middleware создает CORS middleware Fiber. Незаданные поля используют значения Fiber по умолчанию.
+func (c CORSConfig) middleware() fiber.Handler {
+	return cors.New(cors.Config{
+		AllowOrigins:     strings.Join(c.AllowOrigins, ","),
+		AllowMethods:     strings.Join(c.AllowMethods, ","),
+		AllowHeaders:     strings.Join(c.AllowHeaders, ","),
+		AllowCredentials: c.AllowCredentials,
+		MaxAge:           int(c.MaxAge.Seconds()),
+	})
 }
 
 // Server представляет веб-сервер на основе Fiber
 type Server struct {
 	app    *fiber.App
 	config Config
+
+	mu         sync.Mutex
+	onShutdown []func()
 }
 
 // New создает новый экземпляр веб-сервера
 func New(cfg Config) (*Server, error) {
+	if err := cfg.CORS.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Создаем конфигурацию Fiber
 	fiberConfig := fiber.Config{
 		DisableStartupMessage: true,
 		ReadTimeout:           cfg.ReadTimeout,
 		WriteTimeout:          cfg.WriteTimeout,
 		IdleTimeout:           cfg.IdleTimeout,
+		BodyLimit:             cfg.BodyLimit,
 		JSONEncoder: func(v any) ([]byte, error) {
 			return sonic.Marshal(v)
 		},
@@ -48,6 +124,18 @@ func New(cfg Config) (*Server, error) {
 	// Добавляем middleware
 	app.Use(compress.New())
 	app.Use(recover.New())
+	if !cfg.CORS.isZero() {
+		app.Use(cfg.CORS.middleware())
+	}
+	if cfg.EnableRequestID {
+		app.Use(RequestID())
+	}
+	if cfg.EnableRequestLogging {
+		app.Use(LoggingMiddleware(nil))
+	}
+	if cfg.RequestTimeout > 0 {
+		app.Use(Timeout(cfg.RequestTimeout))
+	}
 
 	return &Server{
 		app:    app,
@@ -55,13 +143,56 @@ func New(cfg Config) (*Server, error) {
 	}, nil
 }
 
-// Start запускает веб-сервер
+// Start запускает веб-сервер. При настроенном TLS слушает HTTPS,
+// иначе обычный HTTP.
 func (s *Server) Start() error {
-	return s.app.Listen(s.config.Address)
+	hasCert := s.config.CertFile != "" && s.config.KeyFile != ""
+
+	switch {
+	case s.config.TLSConfig != nil:
+		tlsCfg := s.config.TLSConfig.Clone()
+		if hasCert {
+			cert, err := tls.LoadX509KeyPair(s.config.CertFile, s.config.KeyFile)
+			if err != nil {
+				return fmt.Errorf("failed to load TLS certificate: %w", err)
+			}
+			tlsCfg.Certificates = append(tlsCfg.Certificates, cert)
+		}
+		ln, err := tls.Listen("tcp", s.config.Address, tlsCfg)
+		if err != nil {
+			return err
+		}
+		return s.app.Listener(ln)
+	case hasCert:
+		return s.app.ListenTLS(s.config.Address, s.config.CertFile, s.config.KeyFile)
+	default:
+		return s.app.Listen(s.config.Address)
+	}
+}
+
+// RegisterOnShutdown регистрирует функцию, вызываемую в начале Stop до паузы
+// PreShutdownDelay. Обычно используется для перевода readiness в false.
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onShutdown = append(s.onShutdown, f)
 }
 
-// Stop останавливает веб-сервер
+// Stop останавливает веб-сервер. Сначала вызываются хуки RegisterOnShutdown,
+// затем выдерживается PreShutdownDelay, после чего соединения закрываются
+// с ожиданием не дольше ShutdownTimeout.
 func (s *Server) Stop() error {
+	s.mu.Lock()
+	hooks := slices.Clone(s.onShutdown)
+	s.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+	if s.config.PreShutdownDelay > 0 {
+		time.Sleep(s.config.PreShutdownDelay)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
 	defer cancel()
 	return s.app.ShutdownWithContext(ctx)
@@ -0,0 +1,368 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestCORSConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     CORSConfig
+		wantErr error
+	}{
+		{name: "empty", cfg: CORSConfig{}},
+		{name: "wildcard without credentials", cfg: CORSConfig{AllowOrigins: []string{"*"}}},
+		{
+			name: "explicit origin with credentials",
+			cfg:  CORSConfig{AllowOrigins: []string{"https://app.example.com"}, AllowCredentials: true},
+		},
+		{
+			name:    "wildcard with credentials",
+			cfg:     CORSConfig{AllowOrigins: []string{"https://app.example.com", "*"}, AllowCredentials: true},
+			wantErr: ErrCORSCredentialsWildcard,
+		},
+		{
+			// Без списка origin Fiber разрешает любой origin
+			name:    "default origins with credentials",
+			cfg:     CORSConfig{AllowCredentials: true},
+			wantErr: ErrCORSCredentialsWildcard,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewRejectsCORSCredentialsWildcard(t *testing.T) {
+	_, err := New(Config{CORS: CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}})
+	if !errors.Is(err, ErrCORSCredentialsWildcard) {
+		t.Fatalf("New() error = %v, want ErrCORSCredentialsWildcard", err)
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	s, err := New(Config{CORS: CORSConfig{
+		AllowOrigins:     []string{"https://app.example.com"},
+		AllowMethods:     []string{fiber.MethodGet, fiber.MethodPost},
+		AllowCredentials: true,
+	}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	s.App().Post("/orders", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusCreated) })
+
+	req := httptest.NewRequest(fiber.MethodOptions, "/orders", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://app.example.com")
+	req.Header.Set(fiber.HeaderAccessControlRequestMethod, fiber.MethodPost)
+	resp, err := s.App().Test(req)
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+	if got := resp.Header.Get(fiber.HeaderAccessControlAllowOrigin); got != "https://app.example.com" {
+		t.Errorf("%s = %q, want the request origin", fiber.HeaderAccessControlAllowOrigin, got)
+	}
+	if got := resp.Header.Get(fiber.HeaderAccessControlAllowCredentials); got != "true" {
+		t.Errorf("%s = %q, want true", fiber.HeaderAccessControlAllowCredentials, got)
+	}
+
+	// Чужой origin не получает разрешающих заголовков
+	req = httptest.NewRequest(fiber.MethodOptions, "/orders", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://evil.example.com")
+	req.Header.Set(fiber.HeaderAccessControlRequestMethod, fiber.MethodPost)
+	resp, err = s.App().Test(req)
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+	if got := resp.Header.Get(fiber.HeaderAccessControlAllowOrigin); got != "" {
+		t.Errorf("%s = %q for a foreign origin, want empty", fiber.HeaderAccessControlAllowOrigin, got)
+	}
+}
+
+// freeAddr возвращает свободный локальный адрес
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+// startServer запускает сервер и ждет, пока он начнет принимать соединения.
+// Возвращает канал с результатом Start.
+func startServer(t *testing.T, s *Server) <-chan error {
+	t.Helper()
+
+	done := make(chan error, 1)
+	go func() { done <- s.Start() }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		conn, err := net.Dial("tcp", s.config.Address)
+		if err == nil {
+			conn.Close()
+			return done
+		}
+		select {
+		case err := <-done:
+			t.Fatalf("Start() error = %v", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start on %s", s.config.Address)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// writeTestCert создает самоподписанный сертификат для 127.0.0.1 и
+// возвращает пути к сертификату и ключу
+func writeTestCert(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestStartTLS(t *testing.T) {
+	certFile, keyFile := writeTestCert(t)
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "cert files", cfg: Config{CertFile: certFile, KeyFile: keyFile}},
+		{name: "tls config", cfg: Config{CertFile: certFile, KeyFile: keyFile, TLSConfig: &tls.Config{MinVersion: tls.VersionTLS13}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Address = freeAddr(t)
+			tt.cfg.ShutdownTimeout = time.Second
+			s, err := New(tt.cfg)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			s.App().Get("/", func(c *fiber.Ctx) error { return c.SendString("secure") })
+			done := startServer(t, s)
+
+			resp, err := client.Get("https://" + tt.cfg.Address + "/")
+			if err != nil {
+				t.Fatalf("GET over TLS error = %v", err)
+			}
+			resp.Body.Close()
+			if resp.TLS == nil || resp.StatusCode != http.StatusOK {
+				t.Errorf("response TLS = %v, status = %d, want TLS 200", resp.TLS != nil, resp.StatusCode)
+			}
+			if tt.cfg.TLSConfig != nil && resp.TLS.Version != tls.VersionTLS13 {
+				t.Errorf("TLS version = %x, want TLS 1.3 from TLSConfig", resp.TLS.Version)
+			}
+
+			if err := s.Stop(); err != nil {
+				t.Errorf("Stop() error = %v", err)
+			}
+			if err := <-done; err != nil {
+				t.Errorf("Start() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestStartTLSInvalidCertificate(t *testing.T) {
+	s, err := New(Config{
+		Address:   freeAddr(t),
+		CertFile:  "/nonexistent/cert.pem",
+		KeyFile:   "/nonexistent/key.pem",
+		TLSConfig: &tls.Config{},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := s.Start(); err == nil {
+		t.Error("Start() with missing certificate files should return an error")
+	}
+}
+
+func TestStopRunsShutdownHooksBeforeDelay(t *testing.T) {
+	const delay = 200 * time.Millisecond
+	s, err := New(Config{Address: freeAddr(t), ShutdownTimeout: time.Second, PreShutdownDelay: delay})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	s.App().Get("/", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	done := startServer(t, s)
+
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	record := func(event string) {
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	}
+	hooksDone := make(chan struct{})
+	s.RegisterOnShutdown(func() { record("first hook") })
+	s.RegisterOnShutdown(func() {
+		record("second hook")
+		close(hooksDone)
+	})
+
+	start := time.Now()
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Stop() }()
+
+	// Во время PreShutdownDelay сервер еще обслуживает запросы
+	<-hooksDone
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Get("http://" + s.config.Address + "/")
+	if err != nil {
+		t.Fatalf("request during PreShutdownDelay error = %v", err)
+	}
+	resp.Body.Close()
+	record("request served")
+
+	if err := <-stopped; err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Errorf("Stop() took %v, want at least PreShutdownDelay %v", elapsed, delay)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Start() error = %v", err)
+	}
+
+	want := []string{"first hook", "second hook", "request served"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+	if _, err := client.Get("http://" + s.config.Address + "/"); err == nil {
+		t.Error("server should not accept requests after Stop")
+	}
+}
+
+func TestStopWithoutDelay(t *testing.T) {
+	s, err := New(Config{Address: freeAddr(t), ShutdownTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	done := startServer(t, s)
+
+	called := false
+	s.RegisterOnShutdown(func() { called = true })
+
+	start := time.Now()
+	if err := s.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Stop() took %v without PreShutdownDelay", elapsed)
+	}
+	if !called {
+		t.Error("shutdown hook was not called")
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Start() error = %v", err)
+	}
+}
+
+func TestNewAppliesRequestLimits(t *testing.T) {
+	s, err := New(Config{
+		Address:         freeAddr(t),
+		ShutdownTimeout: time.Second,
+		BodyLimit:       16,
+		RequestTimeout:  50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	s.App().Post("/echo", func(c *fiber.Ctx) error { return c.Send(c.Body()) })
+	s.App().Get("/slow", func(c *fiber.Ctx) error {
+		<-c.UserContext().Done()
+		return c.UserContext().Err()
+	})
+
+	// Лимит тела проверяет fasthttp при чтении запроса, поэтому нужен настоящий сервер
+	done := startServer(t, s)
+	resp, err := http.Post("http://"+s.config.Address+"/echo", "text/plain", strings.NewReader(strings.Repeat("x", 17)))
+	if err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusRequestEntityTooLarge {
+		t.Errorf("body over BodyLimit status = %d, want 413", resp.StatusCode)
+	}
+	if err := s.Stop(); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+	<-done
+
+	resp, err = s.App().Test(httptest.NewRequest(fiber.MethodGet, "/slow", nil), -1)
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+	if resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Errorf("request over RequestTimeout status = %d, want 503", resp.StatusCode)
+	}
+}
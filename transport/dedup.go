@@ -0,0 +1,74 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DedupKeyPrefix префикс ключей, под которыми DedupHandler отмечает события
+const DedupKeyPrefix = "dedup:"
+
+// dedupReleaseTimeout ограничивает снятие отметки после ошибки обработчика
+const dedupReleaseTimeout = 5 * time.Second
+
+// DedupStore хранит отметки обработанных событий. Интерфейс реализует кеш,
+// созданный cache.New, поэтому для дедупликации можно использовать Redis кеш
+// приложения.
+type DedupStore interface {
+	// SetNX сохраняет значение, только если ключ отсутствует, и сообщает,
+	// было ли значение записано
+	SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error)
+	// Delete удаляет значение по ключу
+	Delete(ctx context.Context, key string) error
+}
+
+type dedupHandler struct {
+	inner Handler
+	store DedupStore
+	ttl   time.Duration
+}
+
+// DedupHandler возвращает обработчик, пропускающий повторно доставленные
+// события с уже обработанным EventID. Перед вызовом inner EventID атомарно
+// отмечается через SetNX на ttl; если отметка уже есть, сообщение
+// подтверждается без обработки. При ошибке inner отметка снимается, чтобы
+// повторная доставка или retry обработали событие заново. Дубликат, пришедший
+// во время обработки оригинала, тоже подтверждается: если оригинал завершится
+// ошибкой, его повторит retry, а не дубликат.
+//
+// События без EventID передаются inner без дедупликации.
+func DedupHandler(inner Handler, store DedupStore, ttl time.Duration) Handler {
+	return &dedupHandler{
+		inner: inner,
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+func (h *dedupHandler) Handle(ctx context.Context, envelope Envelope) error {
+	if envelope.EventID == "" {
+		return h.inner.Handle(ctx, envelope)
+	}
+
+	key := DedupKeyPrefix + envelope.EventID
+	marked, err := h.store.SetNX(ctx, key, envelope.OccurredAt, h.ttl)
+	if err != nil {
+		return fmt.Errorf("failed to mark event %s: %w", envelope.EventID, err)
+	}
+	if !marked {
+		return nil
+	}
+
+	if err := h.inner.Handle(ctx, envelope); err != nil {
+		// Контекст обработчика может быть уже отменен, снимаем отметку отдельно
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), dedupReleaseTimeout)
+		defer cancel()
+		if releaseErr := h.store.Delete(releaseCtx, key); releaseErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to release event %s: %w", envelope.EventID, releaseErr))
+		}
+		return err
+	}
+	return nil
+}
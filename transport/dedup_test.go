@@ -0,0 +1,107 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryDedupStore хранит отметки в памяти
+type memoryDedupStore struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func newMemoryDedupStore() *memoryDedupStore {
+	return &memoryDedupStore{keys: make(map[string]bool)}
+}
+
+func (s *memoryDedupStore) SetNX(_ context.Context, key string, _ any, _ time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keys[key] {
+		return false, nil
+	}
+	s.keys[key] = true
+	return true, nil
+}
+
+func (s *memoryDedupStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, key)
+	return nil
+}
+
+// countingHandler считает вызовы и возвращает err
+type countingHandler struct {
+	calls int
+	err   error
+}
+
+func (h *countingHandler) Handle(context.Context, Envelope) error {
+	h.calls++
+	return h.err
+}
+
+func TestDedupHandlerSkipsDuplicate(t *testing.T) {
+	inner := &countingHandler{}
+	store := newMemoryDedupStore()
+	handler := DedupHandler(inner, store, time.Hour)
+	envelope := Envelope{EventID: "evt-1", EventType: "order.created"}
+
+	for i := 0; i < 2; i++ {
+		if err := handler.Handle(context.Background(), envelope); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+	}
+	if inner.calls != 1 {
+		t.Errorf("inner called %d times, want 1", inner.calls)
+	}
+	if !store.keys[DedupKeyPrefix+"evt-1"] {
+		t.Error("processed event should stay marked")
+	}
+}
+
+func TestDedupHandlerReleasesMarkOnError(t *testing.T) {
+	inner := &countingHandler{err: errors.New("db unavailable")}
+	store := newMemoryDedupStore()
+	handler := DedupHandler(inner, store, time.Hour)
+	envelope := Envelope{EventID: "evt-1"}
+
+	if err := handler.Handle(context.Background(), envelope); !errors.Is(err, inner.err) {
+		t.Fatalf("Handle() error = %v, want inner error", err)
+	}
+	if store.keys[DedupKeyPrefix+"evt-1"] {
+		t.Fatal("mark should be released after a handler error")
+	}
+
+	// Повторная доставка обрабатывается заново
+	inner.err = nil
+	if err := handler.Handle(context.Background(), envelope); err != nil {
+		t.Fatalf("Handle() on redelivery error = %v", err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("inner called %d times, want 2", inner.calls)
+	}
+}
+
+func TestDedupHandlerPassesEventsWithoutID(t *testing.T) {
+	inner := &countingHandler{}
+	store := newMemoryDedupStore()
+	handler := DedupHandler(inner, store, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if err := handler.Handle(context.Background(), Envelope{EventType: "order.created"}); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+	}
+	if inner.calls != 2 {
+		t.Errorf("inner called %d times, want 2", inner.calls)
+	}
+	if len(store.keys) != 0 {
+		t.Errorf("store keys = %v, want none", store.keys)
+	}
+}
@@ -0,0 +1,53 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// HandlerError описывает ошибку обработчика с машиночитаемым кодом.
+// RetryProcessor использует Retryable для выбора между повтором и DLQ,
+// а Code передается в заголовке DLQ сообщения.
+type HandlerError struct {
+	Code      string
+	Retryable bool
+	Cause     error
+}
+
+// NewHandlerError создает ошибку обработчика с кодом
+func NewHandlerError(code string, retryable bool, cause error) *HandlerError {
+	return &HandlerError{
+		Code:      code,
+		Retryable: retryable,
+		Cause:     cause,
+	}
+}
+
+func (e *HandlerError) Error() string {
+	if e.Cause == nil {
+		return e.Code
+	}
+	return fmt.Sprintf("%s: %v", e.Code, e.Cause)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Cause
+}
+
+func (e *HandlerError) IsRetryable() bool {
+	return e.Retryable
+}
+
+func (e *HandlerError) RetryAfter() time.Duration {
+	return 0
+}
+
+// ErrorCode возвращает код HandlerError из цепочки ошибок или пустую строку
+func ErrorCode(err error) string {
+	var handlerErr *HandlerError
+	if errors.As(err, &handlerErr) {
+		return handlerErr.Code
+	}
+	return ""
+}
@@ -2,6 +2,8 @@ package transport
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 )
 
 type Handler interface {
@@ -21,3 +23,40 @@ func NewConsumerHandler(handler Handler) *ConsumerHandler {
 func (c *ConsumerHandler) Handle(ctx context.Context, msg Envelope) error {
 	return c.handler.Handle(ctx, msg)
 }
+
+// ErrCodeDecode код HandlerError, который типизированный обработчик
+// возвращает, если не удалось декодировать payload конверта
+const ErrCodeDecode = "decode_error"
+
+type typedHandler[T any] struct {
+	fn func(ctx context.Context, eventID string, payload T) error
+}
+
+// NewTypedHandler возвращает Handler, который декодирует Envelope.Payload в T
+// и вызывает fn. Payload, который не удалось декодировать, возвращается как
+// неповторяемая HandlerError с кодом ErrCodeDecode, поэтому сообщение сразу
+// уходит в DLQ без повторов.
+func NewTypedHandler[T any](fn func(ctx context.Context, eventID string, payload T) error) Handler {
+	return &typedHandler[T]{fn: fn}
+}
+
+func (h *typedHandler[T]) Handle(ctx context.Context, envelope Envelope) error {
+	var payload T
+	if err := json.Unmarshal(envelope.Payload, &payload); err != nil {
+		return NewHandlerError(ErrCodeDecode, false, fmt.Errorf("decode payload of event %s: %w", envelope.EventID, err))
+	}
+	return h.fn(ctx, envelope.EventID, payload)
+}
+
+type topicContextKey struct{}
+
+// ContextWithTopic возвращает копию ctx с топиком, из которого прочитано сообщение
+func ContextWithTopic(ctx context.Context, topic string) context.Context {
+	return context.WithValue(ctx, topicContextKey{}, topic)
+}
+
+// TopicFromContext возвращает топик, из которого прочитано обрабатываемое сообщение
+func TopicFromContext(ctx context.Context) string {
+	topic, _ := ctx.Value(topicContextKey{}).(string)
+	return topic
+}
@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrMessageNotCommitted возвращается Consumer.Run в режиме AckModeOnSuccessOnly,
+// если обработка сообщения не удалась и consumer остановился, не закоммитив его.
+var ErrMessageNotCommitted = errors.New("message failed and was not committed")
+
+// ackAction решение о коммите обработанного сообщения
+type ackAction int
+
+const (
+	// ackCommit коммитит offset сообщения
+	ackCommit ackAction = iota
+	// ackSkip не коммитит сообщение, но позволяет закоммитить следующие
+	// сообщения партиции
+	ackSkip
+	// ackHold не коммитит ни сообщение, ни следующие сообщения партиции,
+	// поэтому они будут доставлены повторно
+	ackHold
+)
+
+type ackKey struct{}
+
+// contextWithAck возвращает контекст, через который обработчик подтверждает
+// сообщение вызовом Commit
+func contextWithAck(ctx context.Context) (context.Context, *atomic.Bool) {
+	acked := &atomic.Bool{}
+	return context.WithValue(ctx, ackKey{}, acked), acked
+}
+
+// Commit подтверждает обрабатываемое сообщение в режиме AckModeManual. Offset
+// коммитится после возврата из обработчика в порядке партиции. Возвращает
+// false, если ctx не принадлежит обработчику Kafka consumer. В остальных
+// режимах подтверждения вызов ни на что не влияет.
+func Commit(ctx context.Context) bool {
+	acked, ok := ctx.Value(ackKey{}).(*atomic.Bool)
+	if !ok {
+		return false
+	}
+	acked.Store(true)
+	return true
+}
@@ -0,0 +1,70 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Admin управляет топиками, используя брокеры и настройки SASL/TLS из Config.
+// Предназначен для подготовки топиков в разработке и интеграционных тестах.
+type Admin struct {
+	client *kafka.Client
+}
+
+// NewAdmin создает Admin. Подключение к брокерам происходит лениво при первом вызове.
+func NewAdmin(cfg Config) (*Admin, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("at least one broker is required")
+	}
+
+	adminTransport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Admin{
+		client: &kafka.Client{
+			Addr:      kafka.TCP(cfg.Brokers...),
+			Timeout:   10 * time.Second,
+			Transport: adminTransport,
+		},
+	}, nil
+}
+
+// EnsureTopic создает топик, если он еще не существует. Число партиций и
+// фактор репликации существующего топика не меняются.
+func (a *Admin) EnsureTopic(ctx context.Context, name string, partitions, replication int) error {
+	resp, err := a.client.CreateTopics(ctx, &kafka.CreateTopicsRequest{
+		Topics: []kafka.TopicConfig{{
+			Topic:             name,
+			NumPartitions:     partitions,
+			ReplicationFactor: replication,
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create topic %s: %w", name, err)
+	}
+	if err := resp.Errors[name]; err != nil && !errors.Is(err, kafka.TopicAlreadyExists) {
+		return fmt.Errorf("failed to create topic %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteTopic удаляет топик. Удаление несуществующего топика не считается
+// ошибкой.
+func (a *Admin) DeleteTopic(ctx context.Context, name string) error {
+	resp, err := a.client.DeleteTopics(ctx, &kafka.DeleteTopicsRequest{
+		Topics: []string{name},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete topic %s: %w", name, err)
+	}
+	if err := resp.Errors[name]; err != nil && !errors.Is(err, kafka.UnknownTopicOrPartition) {
+		return fmt.Errorf("failed to delete topic %s: %w", name, err)
+	}
+	return nil
+}
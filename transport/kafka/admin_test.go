@@ -0,0 +1,17 @@
+package kafka
+
+import "testing"
+
+func TestNewAdmin(t *testing.T) {
+	if _, err := NewAdmin(Config{}); err == nil {
+		t.Error("NewAdmin() without brokers should fail")
+	}
+
+	admin, err := NewAdmin(Config{Brokers: []string{"localhost:9092"}})
+	if err != nil {
+		t.Fatalf("NewAdmin() error = %v", err)
+	}
+	if admin.client.Addr.String() != "localhost:9092" {
+		t.Errorf("client address = %s, want localhost:9092", admin.client.Addr)
+	}
+}
@@ -0,0 +1,172 @@
+package kafka
+
+import (
+	"sync"
+	"time"
+
+	platformlogger "gitlab.com/zynero/shared/logger"
+)
+
+// CircuitState состояние circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed пропускает все вызовы
+	CircuitClosed CircuitState = iota
+	// CircuitHalfOpen пропускает ограниченное число пробных вызовов
+	CircuitHalfOpen
+	// CircuitOpen отклоняет все вызовы до истечения таймаута
+	CircuitOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitHalfOpen:
+		return "half-open"
+	case CircuitOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// stateChange переход состояния, о котором нужно уведомить onChange
+type stateChange struct {
+	from, to CircuitState
+}
+
+// CircuitBreaker защищает отказывающий обработчик. Размыкается после
+// FailureThreshold ошибок подряд, отклоняет вызовы в течение Timeout, затем
+// переходит в half-open и пропускает до MaxRequests пробных вызовов.
+// SuccessThreshold успешных проб подряд замыкают его, любая неуспешная проба
+// размыкает снова.
+type CircuitBreaker struct {
+	config   CircuitBreakerConfig
+	onChange func(from, to CircuitState)
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	successes int
+	inFlight  int
+	openedAt  time.Time
+	now       func() time.Time
+	logger    *platformlogger.Logger
+	pending   []stateChange // переходы, еще не переданные в onChange
+}
+
+// NewCircuitBreaker создает замкнутый circuit breaker. onChange, если задан,
+// вызывается при каждом переходе состояния вне блокировки, поэтому из него
+// можно обращаться к самому circuit breaker.
+func NewCircuitBreaker(config CircuitBreakerConfig, onChange func(from, to CircuitState)) *CircuitBreaker {
+	return &CircuitBreaker{
+		config:   config,
+		onChange: onChange,
+		now:      time.Now,
+		logger:   defaultLogger(),
+	}
+}
+
+// SetLogger устанавливает логгер для сообщений о смене состояния
+func (cb *CircuitBreaker) SetLogger(l *platformlogger.Logger) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.logger = l
+}
+
+// State возвращает текущее состояние
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.unlock()
+	cb.refresh()
+	return cb.state
+}
+
+// Allow сообщает, можно ли выполнить вызов. После каждого разрешенного
+// вызова нужно вызвать Record.
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.unlock()
+	cb.refresh()
+
+	switch cb.state {
+	case CircuitOpen:
+		return false
+	case CircuitHalfOpen:
+		if cb.inFlight >= max(cb.config.MaxRequests, 1) {
+			return false
+		}
+		cb.inFlight++
+	}
+	return true
+}
+
+// Record сообщает результат вызова, разрешенного Allow
+func (cb *CircuitBreaker) Record(success bool) {
+	cb.mu.Lock()
+	defer cb.unlock()
+
+	switch cb.state {
+	case CircuitClosed:
+		if success {
+			cb.failures = 0
+			return
+		}
+		cb.failures++
+		if cb.failures >= max(cb.config.FailureThreshold, 1) {
+			cb.transition(CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		if cb.inFlight > 0 {
+			cb.inFlight--
+		}
+		if !success {
+			cb.transition(CircuitOpen)
+			return
+		}
+		cb.successes++
+		if cb.successes >= max(cb.config.SuccessThreshold, 1) {
+			cb.transition(CircuitClosed)
+		}
+	}
+}
+
+// refresh переводит разомкнутый circuit breaker в half-open по истечении Timeout
+func (cb *CircuitBreaker) refresh() {
+	if cb.state == CircuitOpen && cb.now().Sub(cb.openedAt) >= cb.config.Timeout {
+		cb.transition(CircuitHalfOpen)
+	}
+}
+
+// transition меняет состояние и сбрасывает счетчики. Вызывается под mu,
+// onChange вызывается позже в unlock.
+func (cb *CircuitBreaker) transition(to CircuitState) {
+	from := cb.state
+	cb.state = to
+	cb.failures = 0
+	cb.successes = 0
+	cb.inFlight = 0
+	if to == CircuitOpen {
+		cb.openedAt = cb.now()
+	}
+
+	cb.logger.Warn().
+		Str("from", from.String()).
+		Str("to", to.String()).
+		Msg("Circuit breaker state changed")
+
+	if cb.onChange != nil {
+		cb.pending = append(cb.pending, stateChange{from: from, to: to})
+	}
+}
+
+// unlock освобождает mu и затем уведомляет onChange о накопленных переходах
+func (cb *CircuitBreaker) unlock() {
+	changes := cb.pending
+	cb.pending = nil
+	cb.mu.Unlock()
+
+	for _, c := range changes {
+		cb.onChange(c.from, c.to)
+	}
+}
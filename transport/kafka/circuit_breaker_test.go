@@ -0,0 +1,136 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestBreaker создает circuit breaker с управляемыми часами и записью переходов
+func newTestBreaker(cfg CircuitBreakerConfig) (*CircuitBreaker, *time.Time, *[]string) {
+	now := time.Unix(1700000000, 0)
+	var changes []string
+	var cb *CircuitBreaker
+	cb = NewCircuitBreaker(cfg, func(from, to CircuitState) {
+		// onChange вызывается без блокировки, обращение к State не зависает
+		_ = cb.State()
+		changes = append(changes, from.String()+"->"+to.String())
+	})
+	cb.now = func() time.Time { return now }
+	return cb, &now, &changes
+}
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	cb, _, changes := newTestBreaker(CircuitBreakerConfig{FailureThreshold: 3, Timeout: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		cb.Allow()
+		cb.Record(false)
+	}
+	// Успешный вызов сбрасывает счетчик ошибок подряд
+	cb.Allow()
+	cb.Record(true)
+	for i := 0; i < 2; i++ {
+		cb.Allow()
+		cb.Record(false)
+	}
+	if cb.State() != CircuitClosed {
+		t.Fatalf("state = %v, want closed before the threshold", cb.State())
+	}
+
+	cb.Allow()
+	cb.Record(false)
+	if cb.State() != CircuitOpen {
+		t.Fatalf("state = %v, want open after 3 consecutive failures", cb.State())
+	}
+	if cb.Allow() {
+		t.Error("open breaker should reject calls")
+	}
+	if want := []string{"closed->open"}; !reflect.DeepEqual(*changes, want) {
+		t.Errorf("changes = %v, want %v", *changes, want)
+	}
+}
+
+func TestCircuitBreakerHalfOpensAfterTimeout(t *testing.T) {
+	cb, now, _ := newTestBreaker(CircuitBreakerConfig{FailureThreshold: 1, Timeout: time.Minute, MaxRequests: 1})
+	cb.Allow()
+	cb.Record(false)
+
+	*now = now.Add(59 * time.Second)
+	if cb.Allow() {
+		t.Fatal("breaker should stay open until the timeout elapses")
+	}
+
+	*now = now.Add(time.Second)
+	if cb.State() != CircuitHalfOpen {
+		t.Fatalf("state = %v, want half-open after the timeout", cb.State())
+	}
+}
+
+func TestCircuitBreakerLimitsProbes(t *testing.T) {
+	cb, now, _ := newTestBreaker(CircuitBreakerConfig{
+		FailureThreshold: 1, SuccessThreshold: 5, Timeout: time.Second, MaxRequests: 2,
+	})
+	cb.Allow()
+	cb.Record(false)
+	*now = now.Add(time.Second)
+
+	if !cb.Allow() || !cb.Allow() {
+		t.Fatal("half-open breaker should allow MaxRequests probes")
+	}
+	if cb.Allow() {
+		t.Fatal("half-open breaker should reject probes beyond MaxRequests")
+	}
+
+	// Завершенная проба освобождает место для следующей
+	cb.Record(true)
+	if !cb.Allow() {
+		t.Error("finished probe should free a slot")
+	}
+}
+
+func TestCircuitBreakerClosesAfterSuccesses(t *testing.T) {
+	cb, now, changes := newTestBreaker(CircuitBreakerConfig{
+		FailureThreshold: 1, SuccessThreshold: 2, Timeout: time.Second, MaxRequests: 1,
+	})
+	cb.Allow()
+	cb.Record(false)
+	*now = now.Add(time.Second)
+
+	cb.Allow()
+	cb.Record(true)
+	if cb.State() != CircuitHalfOpen {
+		t.Fatalf("state = %v, want half-open after one success", cb.State())
+	}
+	cb.Allow()
+	cb.Record(true)
+	if cb.State() != CircuitClosed {
+		t.Fatalf("state = %v, want closed after SuccessThreshold successes", cb.State())
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if !reflect.DeepEqual(*changes, want) {
+		t.Errorf("changes = %v, want %v", *changes, want)
+	}
+}
+
+func TestCircuitBreakerFailedProbeReopens(t *testing.T) {
+	cb, now, _ := newTestBreaker(CircuitBreakerConfig{
+		FailureThreshold: 1, SuccessThreshold: 2, Timeout: time.Second, MaxRequests: 1,
+	})
+	cb.Allow()
+	cb.Record(false)
+	*now = now.Add(time.Second)
+
+	cb.Allow()
+	cb.Record(false)
+	if cb.State() != CircuitOpen {
+		t.Fatalf("state = %v, want open after a failed probe", cb.State())
+	}
+
+	// Таймаут отсчитывается заново от повторного размыкания
+	*now = now.Add(time.Second - time.Millisecond)
+	if cb.Allow() {
+		t.Error("reopened breaker should wait a full timeout")
+	}
+}
@@ -1,6 +1,7 @@
 package kafka
 
 import (
+	"errors"
 	"time"
 
 	"github.com/segmentio/kafka-go"
@@ -10,9 +11,11 @@ import (
 type Config struct {
 	Brokers     []string          `mapstructure:"brokers" validate:"required,min=1"`
 	SASL        *SASLConfig       `mapstructure:"sasl"`
+	TLS         *TLSConfig        `mapstructure:"tls"`
 	Producer    ProducerConfig    `mapstructure:"producer"`
 	Consumer    ConsumerConfig    `mapstructure:"consumer"`
 	Reliability ReliabilityConfig `mapstructure:"reliability"`
+	Tracing     TracingConfig     `mapstructure:"tracing"`
 }
 
 // SASLConfig describes SASL authentication settings.
@@ -33,8 +36,54 @@ type ProducerConfig struct {
 	RequiredAcks int           `mapstructure:"required_acks" validate:"oneof=-1 0 1"`
 	MaxRetries   int           `mapstructure:"max_retries" validate:"min=0,max=10"`
 	RetryBackoff time.Duration `mapstructure:"retry_backoff" validate:"min=1ms"`
+	// Partition balancer: hash (default), roundrobin, leastbytes or murmur2.
+	// murmur2 places keys on the same partitions as the Java client.
+	Balancer string `mapstructure:"balancer" validate:"omitempty,oneof=hash roundrobin round-robin leastbytes least-bytes murmur2"`
+	// Idempotent requires acknowledgement from all in-sync replicas. kafka-go
+	// does not implement producer IDs, so retries may still duplicate messages
+	// and consumers should deduplicate by key.
+	Idempotent bool `mapstructure:"idempotent"`
+	// CloseTimeout bounds Close waiting for in-flight messages. Zero means
+	// DefaultCloseTimeout.
+	CloseTimeout time.Duration `mapstructure:"close_timeout" validate:"min=0"`
+	// WriteTimeout bounds a single write to the brokers. Zero keeps the
+	// kafka-go default of 10s.
+	WriteTimeout time.Duration `mapstructure:"write_timeout" validate:"min=0"`
+	// Async makes Publish return before delivery. Delivery results are then
+	// reported only to metrics, the log and the KafkaProducer.SetCompletion
+	// callback.
+	Async bool `mapstructure:"async"`
 }
 
+// DefaultCloseTimeout is used when ProducerConfig.CloseTimeout is not set.
+const DefaultCloseTimeout = 10 * time.Second
+
+// Commit strategies for ConsumerConfig.CommitStrategy.
+const (
+	CommitStrategySync     = "sync"
+	CommitStrategyInterval = "interval"
+)
+
+// Acknowledgement modes for ConsumerConfig.AckMode.
+const (
+	AckModeAuto          = "auto"
+	AckModeManual        = "manual"
+	AckModeOnSuccessOnly = "on-success-only"
+)
+
+// DLQ naming strategies for ReliabilityConfig.DLQNaming.
+const (
+	DLQNamingSingle = "single"
+	DLQNamingSuffix = "suffix"
+)
+
+// DefaultDLQSuffix is used when ReliabilityConfig.DLQSuffix is not set.
+const DefaultDLQSuffix = ".dlq"
+
+// ErrIdempotenceRequiresAcksAll is returned when idempotent writes are enabled
+// without required_acks=-1.
+var ErrIdempotenceRequiresAcksAll = errors.New("idempotent producer requires required_acks=-1")
+
 // ConsumerConfig holds consumer related settings.
 type ConsumerConfig struct {
 	GroupID           string        `mapstructure:"group_id" validate:"required"`
@@ -48,6 +97,64 @@ type ConsumerConfig struct {
 	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval" validate:"min=1s"`
 	SessionTimeout    time.Duration `mapstructure:"session_timeout" validate:"min=1s"`
 	RebalanceTimeout  time.Duration `mapstructure:"rebalance_timeout" validate:"min=1s"`
+	// CommitStrategy selects how offsets are committed:
+	//   - sync (default): each message is committed synchronously after
+	//     processing; nothing processed is lost, at the cost of throughput.
+	//   - interval: commits are batched and flushed every CommitInterval;
+	//     on a crash messages processed since the last flush are redelivered.
+	CommitStrategy string `mapstructure:"commit_strategy" validate:"omitempty,oneof=sync interval"`
+	// AckMode selects which processed messages are committed:
+	//   - auto (default): every message is committed, failed ones too, since
+	//     retry/DLQ already handled them; with DLQ disabled they are lost.
+	//   - on-success-only: a failed message is never committed; the consumer
+	//     stops with ErrMessageNotCommitted and redelivers it after restart.
+	//   - manual: only messages acknowledged by the handler via Commit are
+	//     committed. Committing an offset also confirms earlier messages of
+	//     the partition.
+	AckMode string `mapstructure:"ack_mode" validate:"omitempty,oneof=auto manual on-success-only"`
+	// Concurrency sets the number of workers. Messages with the same key
+	// (or of the same partition when the key is empty) are always handled by
+	// the same worker, in order. An offset is committed only after every
+	// earlier message of its partition is processed. 0 or 1 keeps sequential
+	// processing.
+	Concurrency int `mapstructure:"concurrency" validate:"min=0"`
+	// LagInterval sets how often consumer group lag is exported to metrics.
+	// Zero means DefaultLagInterval.
+	LagInterval time.Duration `mapstructure:"lag_interval" validate:"min=0"`
+	// SkipOversized skips a message larger than MaxBytes instead of retrying
+	// the read forever and stalling the partition. The skipped offset is
+	// logged, committed and, with DLQ enabled, reported to the DLQ as a record
+	// without payload. Disabled by default: such messages are never lost
+	// silently.
+	SkipOversized bool `mapstructure:"skip_oversized"`
+	// RateLimit limits processing per message key. Disabled by default.
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+}
+
+// Actions for RateLimitConfig.Action.
+const (
+	RateLimitActionDelay = "delay"
+	RateLimitActionPark  = "park"
+)
+
+// RateLimitConfig configures a token bucket per message key, so one noisy key
+// (e.g. a tenant) cannot starve the others. Messages without a key are not
+// limited.
+type RateLimitConfig struct {
+	// Rate is the number of messages per second allowed for a key. Zero
+	// disables the limiter.
+	Rate float64 `mapstructure:"rate" validate:"min=0"`
+	// Burst is the bucket size. Zero means Rate rounded up, at least 1.
+	Burst int `mapstructure:"burst" validate:"min=0"`
+	// Overrides sets Rate for individual keys; zero removes the limit.
+	Overrides map[string]float64 `mapstructure:"overrides"`
+	// Action selects what happens to a message over the limit:
+	//   - delay (default): the message waits for a token. Only the worker of
+	//     its key waits, so with Concurrency > 1 other keys keep going.
+	//   - park: the message is published to ParkingTopic and committed
+	//     without processing. If publishing fails, it is delayed instead.
+	Action       string `mapstructure:"action" validate:"omitempty,oneof=delay park"`
+	ParkingTopic string `mapstructure:"parking_topic" validate:"required_if=Action park"`
 }
 
 // ReliabilityConfig configures retry and DLQ behaviour.
@@ -57,6 +164,7 @@ type ReliabilityConfig struct {
 	RetryBackoff           time.Duration `mapstructure:"retry_backoff" validate:"min=1ms"`                 // base delay between retries
 	RetryBackoffMultiplier float64       `mapstructure:"retry_backoff_multiplier" validate:"min=1,max=10"` // multiplier for exponential backoff
 	MaxRetryBackoff        time.Duration `mapstructure:"max_retry_backoff" validate:"min=1s"`              // upper limit for backoff
+	MaxTotalRetries        int           `mapstructure:"max_total_retries" validate:"min=0"`               // ceiling across redeliveries, 0 disables it
 
 	// Dead Letter Queue options
 	DLQTopic           string `mapstructure:"dlq_topic"`            // target topic for DLQ messages
@@ -64,6 +172,14 @@ type ReliabilityConfig struct {
 	DLQRetryHeader     string `mapstructure:"dlq_retry_header"`     // header storing retry count
 	DLQErrorHeader     string `mapstructure:"dlq_error_header"`     // header storing error message
 	DLQTimestampHeader string `mapstructure:"dlq_timestamp_header"` // header storing failure timestamp
+	ParkingTopic       string `mapstructure:"parking_topic"`        // topic for messages over MaxTotalRetries, discarded when empty
+	// DLQNaming selects the DLQ topic of a failed message:
+	//   - single (default): every message goes to DLQTopic.
+	//   - suffix: a message goes to its original topic name plus DLQSuffix,
+	//     so each consumed topic has its own DLQ. Missing topics are created
+	//     with the broker default partitions and replication.
+	DLQNaming string `mapstructure:"dlq_naming" validate:"omitempty,oneof=single suffix"`
+	DLQSuffix string `mapstructure:"dlq_suffix"` // appended to the topic with suffix naming, DefaultDLQSuffix when empty
 
 	// Other options
 	EnableMetrics        bool                 `mapstructure:"enable_metrics"`  // expose Prometheus metrics
@@ -97,6 +213,54 @@ func (pc *ProducerConfig) GetCompressionCodec() kafka.Compression {
 	}
 }
 
+// GetBalancer converts the configured balancer string to kafka.Balancer.
+func (pc *ProducerConfig) GetBalancer() kafka.Balancer {
+	switch pc.Balancer {
+	case "roundrobin", "round-robin":
+		return &kafka.RoundRobin{}
+	case "leastbytes", "least-bytes":
+		return &kafka.LeastBytes{}
+	case "murmur2":
+		return &kafka.Murmur2Balancer{}
+	default:
+		return &kafka.Hash{} // По умолчанию hash
+	}
+}
+
+// GetRequiredAcks returns acknowledgement mode for the writer.
+func (pc *ProducerConfig) GetRequiredAcks() kafka.RequiredAcks {
+	if pc.Idempotent {
+		return kafka.RequireAll
+	}
+	return kafka.RequiredAcks(pc.RequiredAcks)
+}
+
+// Validate checks producer settings that depend on each other.
+func (pc *ProducerConfig) Validate() error {
+	if pc.Idempotent && kafka.RequiredAcks(pc.RequiredAcks) != kafka.RequireAll {
+		return ErrIdempotenceRequiresAcksAll
+	}
+	return nil
+}
+
+// GetCommitInterval returns the reader commit interval for the configured
+// strategy. Zero means synchronous commits.
+func (cc *ConsumerConfig) GetCommitInterval() time.Duration {
+	if cc.CommitStrategy == CommitStrategyInterval {
+		return cc.CommitInterval
+	}
+	return 0
+}
+
+// GetStartOffset converts the configured start offset to the kafka-go value.
+// It only applies to consumer groups without a committed offset.
+func (cc *ConsumerConfig) GetStartOffset() int64 {
+	if cc.StartOffset == "latest" {
+		return kafka.LastOffset
+	}
+	return kafka.FirstOffset // По умолчанию earliest, как в kafka-go
+}
+
 // GetRetryBackoffWithJitter calculates retry delay with jitter applied.
 func (rc *ReliabilityConfig) GetRetryBackoffWithJitter(attempt int) time.Duration {
 	backoff := rc.RetryBackoff
@@ -110,6 +274,18 @@ func (rc *ReliabilityConfig) GetRetryBackoffWithJitter(attempt int) time.Duratio
 	return backoff
 }
 
+// DLQTopicFor returns the DLQ topic for messages consumed from topic, or an
+// empty string when the DLQ is not configured.
+func (rc *ReliabilityConfig) DLQTopicFor(topic string) string {
+	if rc.DLQNaming != DLQNamingSuffix {
+		return rc.DLQTopic
+	}
+	if rc.DLQSuffix == "" {
+		return topic + DefaultDLQSuffix
+	}
+	return topic + rc.DLQSuffix
+}
+
 // GetDefaultReliabilityConfig returns default reliability settings.
 func GetDefaultReliabilityConfig() ReliabilityConfig {
 	return ReliabilityConfig{
@@ -121,6 +297,8 @@ func GetDefaultReliabilityConfig() ReliabilityConfig {
 		DLQRetryHeader:         "x-retry-count",
 		DLQErrorHeader:         "x-error-message",
 		DLQTimestampHeader:     "x-failed-timestamp",
+		DLQNaming:              DLQNamingSingle,
+		DLQSuffix:              DefaultDLQSuffix,
 		EnableMetrics:          false,
 	}
 }
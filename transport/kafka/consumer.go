@@ -4,63 +4,148 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"sync"
 	"time"
 
+	platformlogger "gitlab.com/zynero/shared/logger"
 	"gitlab.com/zynero/shared/transport"
 
-	json "github.com/bytedance/sonic"
-	"github.com/rs/zerolog/log"
-
 	"github.com/segmentio/kafka-go"
 )
 
 type Consumer struct {
 	reader         *kafka.Reader
+	readerCfg      kafka.ReaderConfig
 	handler        transport.Handler
 	retryProcessor *RetryProcessor
 	metrics        transport.Metrics
-	topic          string
+	topics         []string
+	tracing        TracingConfig
+	concurrency    int
+	ackMode        string
+	skipOversized  bool
+	limiter        *keyLimiter  // nil без RateLimit
+	lag            *lagReporter // nil без consumer group
+	logger         *platformlogger.Logger
+
+	// Producer и топик парковки для RateLimitActionPark
+	rateLimitProducer *KafkaProducer
+	rateLimitTopic    string
 
 	// Каналы для graceful shutdown
 	stopCh    chan struct{}
+	stopOnce  sync.Once
 	doneCh    chan struct{}
 	mu        sync.RWMutex
 	isRunning bool
 }
 
-func NewConsumer(cfg Config, topic string, handler transport.Handler) *Consumer {
+// NewConsumer создает consumer топика topic. Возвращает ошибку, если не
+// удалось настроить SASL/TLS.
+func NewConsumer(cfg Config, topic string, handler transport.Handler) (*Consumer, error) {
+	readerCfg := readerConfig(cfg)
+	readerCfg.Topic = topic
+	return newConsumer(cfg, readerCfg, []string{topic}, handler)
+}
+
+// NewMultiConsumer создает consumer, читающий несколько топиков в рамках одной
+// consumer group. Топик сообщения доступен обработчику через
+// transport.TopicFromContext.
+func NewMultiConsumer(cfg Config, topics []string, handler transport.Handler) (*Consumer, error) {
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("at least one topic is required")
+	}
+	if cfg.Consumer.GroupID == "" {
+		return nil, fmt.Errorf("group id is required to consume multiple topics")
+	}
+
+	readerCfg := readerConfig(cfg)
+	readerCfg.GroupTopics = topics
+	return newConsumer(cfg, readerCfg, topics, handler)
+}
+
+// readerConfig собирает общие настройки kafka.Reader из конфигурации consumer
+func readerConfig(cfg Config) kafka.ReaderConfig {
+	return kafka.ReaderConfig{
+		Brokers:           cfg.Brokers,
+		GroupID:           cfg.Consumer.GroupID,
+		MinBytes:          cfg.Consumer.MinBytes,
+		MaxBytes:          cfg.Consumer.MaxBytes,
+		MaxWait:           cfg.Consumer.MaxWait,
+		StartOffset:       cfg.Consumer.GetStartOffset(),
+		CommitInterval:    cfg.Consumer.GetCommitInterval(),
+		HeartbeatInterval: cfg.Consumer.HeartbeatInterval,
+		SessionTimeout:    cfg.Consumer.SessionTimeout,
+		RebalanceTimeout:  cfg.Consumer.RebalanceTimeout,
+	}
+}
+
+func newConsumer(cfg Config, readerCfg kafka.ReaderConfig, topics []string, handler transport.Handler) (*Consumer, error) {
+	// Без SASL/TLS подключаться нельзя: это открыло бы незащищенное соединение
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	readerCfg.Dialer = dialer
+
 	consumer := &Consumer{
-		reader: kafka.NewReader(kafka.ReaderConfig{
-			Brokers:        cfg.Brokers,
-			Topic:          topic,
-			GroupID:        cfg.Consumer.GroupID,
-			MinBytes:       cfg.Consumer.MinBytes,
-			MaxBytes:       cfg.Consumer.MaxBytes,
-			MaxWait:        cfg.Consumer.MaxWait,
-			CommitInterval: 0,
-		}),
-		handler: handler,
-		topic:   topic,
-		stopCh:  make(chan struct{}),
-		doneCh:  make(chan struct{}),
-		metrics: &transport.NoOpMetrics{}, // По умолчанию no-op метрики
+		reader:    kafka.NewReader(readerCfg),
+		readerCfg: readerCfg,
+		handler:   handler,
+		topics:    topics,
+		tracing:   cfg.Tracing,
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+		metrics:   &transport.NoOpMetrics{}, // По умолчанию no-op метрики
+		logger:    defaultLogger(),
+
+		concurrency:   cfg.Consumer.Concurrency,
+		ackMode:       cfg.Consumer.AckMode,
+		skipOversized: cfg.Consumer.SkipOversized,
+	}
+
+	if rl := cfg.Consumer.RateLimit; rl.Rate > 0 || len(rl.Overrides) > 0 {
+		consumer.limiter = newKeyLimiter(rl)
+		if rl.Action == RateLimitActionPark {
+			if producer, err := NewProducer(cfg); err != nil {
+				consumer.logger.Error().Err(err).Msg("Failed to create rate limit parking producer, delaying instead")
+			} else {
+				consumer.rateLimitProducer = producer
+				consumer.rateLimitTopic = rl.ParkingTopic
+			}
+		}
+	}
+
+	if lag, err := newLagReporter(cfg, topics); err != nil {
+		consumer.logger.Error().Err(err).Msg("Failed to configure consumer lag metric")
+	} else {
+		consumer.lag = lag
 	}
 
 	// Создаем retry processor если настроена надежность
 	if cfg.Reliability.RetryCount > 0 || cfg.Reliability.DLQEnabled {
 		// Для DLQ нужен producer
-		if cfg.Reliability.DLQEnabled && cfg.Reliability.DLQTopic != "" {
+		if cfg.Reliability.DLQEnabled && cfg.Reliability.DLQTopicFor(topics[0]) != "" {
 			dlqProducer, err := NewProducer(cfg)
 			if err != nil {
-				log.Error().Err(err).Msg("Failed to create DLQ producer, disabling retry")
+				consumer.logger.Error().Err(err).Msg("Failed to create DLQ producer, disabling retry")
 			} else {
 				consumer.retryProcessor = NewRetryProcessor(cfg.Reliability, dlqProducer)
 			}
 		}
+
+		// Для DLQ на каждый топик создаем недостающие топики через admin
+		if consumer.retryProcessor != nil && cfg.Reliability.DLQNaming == DLQNamingSuffix {
+			if admin, err := NewAdmin(cfg); err != nil {
+				consumer.logger.Error().Err(err).Msg("Failed to create admin for DLQ topics")
+			} else {
+				consumer.retryProcessor.SetAdmin(admin)
+			}
+		}
 	}
 
-	return consumer
+	return consumer, nil
 }
 
 // SetMetrics устанавливает интерфейс метрик
@@ -73,6 +158,24 @@ func (c *Consumer) SetMetrics(metrics transport.Metrics) {
 	if c.retryProcessor != nil {
 		c.retryProcessor.SetMetrics(metrics)
 	}
+	if c.rateLimitProducer != nil {
+		c.rateLimitProducer.SetMetrics(metrics)
+	}
+}
+
+// SetLogger устанавливает логгер consumer и его retry processor. По умолчанию
+// используется глобальный логгер с полем component=kafka.
+func (c *Consumer) SetLogger(l *platformlogger.Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logger = l
+
+	if c.retryProcessor != nil {
+		c.retryProcessor.SetLogger(l)
+	}
+	if c.rateLimitProducer != nil {
+		c.rateLimitProducer.SetLogger(l)
+	}
 }
 
 // Run запускает consumer и блокирует выполнение до получения сигнала остановки
@@ -97,10 +200,10 @@ func (c *Consumer) Run(ctx context.Context) error {
 		// Обновляем метрики
 		c.metrics.SetActiveConsumers(0)
 
-		log.Info().Msg("Consumer stopped")
+		c.logger.Info().Msg("Consumer stopped")
 	}()
 
-	log.Info().Msg("Starting consumer")
+	c.logger.Info().Strs("topics", c.topics).Msg("Starting consumer")
 
 	// Создаем контекст с отменой для внутреннего использования
 	consumerCtx, cancel := context.WithCancel(ctx)
@@ -110,14 +213,19 @@ func (c *Consumer) Run(ctx context.Context) error {
 	go func() {
 		select {
 		case <-c.stopCh:
-			log.Info().Msg("Received stop signal")
+			c.logger.Info().Msg("Received stop signal")
 			cancel()
 		case <-ctx.Done():
-			log.Info().Msg("Context cancelled")
+			c.logger.Info().Msg("Context cancelled")
 			cancel()
 		}
 	}()
 
+	// Отставание обновляется по таймеру независимо от потока сообщений
+	if c.lag != nil {
+		go c.lag.run(consumerCtx, c)
+	}
+
 	return c.processMessages(consumerCtx)
 }
 
@@ -130,8 +238,8 @@ func (c *Consumer) Stop() {
 	}
 	c.mu.RUnlock()
 
-	log.Info().Msg("Stopping consumer...")
-	close(c.stopCh)
+	c.logger.Info().Msg("Stopping consumer...")
+	c.stopOnce.Do(func() { close(c.stopCh) })
 }
 
 // Wait ожидает завершения работы consumer с таймаутом
@@ -150,93 +258,232 @@ func (c *Consumer) Close() error {
 
 	// Ждем завершения с таймаутом
 	if err := c.Wait(30 * time.Second); err != nil {
-		log.Warn().Err(err).Msg("Consumer did not stop gracefully, forcing close")
+		c.logger.Warn().Err(err).Msg("Consumer did not stop gracefully, forcing close")
 	}
 
-	if err := c.reader.Close(); err != nil {
-		log.Error().Err(err).Msg("Error closing Kafka reader")
+	if c.rateLimitProducer != nil {
+		if err := c.rateLimitProducer.Close(); err != nil {
+			c.logger.Error().Err(err).Msg("Error closing rate limit parking producer")
+		}
+	}
+
+	if err := c.currentReader().Close(); err != nil {
+		c.logger.Error().Err(err).Msg("Error closing Kafka reader")
 		return fmt.Errorf("failed to close reader: %w", err)
 	}
 
-	log.Info().Msg("Consumer closed successfully")
+	c.logger.Info().Msg("Consumer closed successfully")
 	return nil
 }
 
-// processMessages основной цикл обработки сообщений
+// processMessages основной цикл обработки сообщений. При Concurrency > 1
+// сообщения распределяются по воркерам так, что сообщения с одним ключом
+// (без ключа - одной партиции) всегда обрабатываются одним воркером по
+// порядку. Offset коммитится только когда обработаны все предыдущие
+// сообщения партиции.
 func (c *Consumer) processMessages(ctx context.Context) error {
-	for {
-		select {
-		case <-ctx.Done():
-			log.Info().Msg("Context cancelled, stopping message processing")
-			return nil
-		default:
-			// Устанавливаем таймаут для чтения сообщений
-			readCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-			msg, err := c.reader.ReadMessage(readCtx)
-			cancel()
+	if c.concurrency <= 1 {
+		for {
+			msg, ok := c.fetchMessage(ctx)
+			if !ok {
+				return nil
+			}
+			if err := c.handleMessage(ctx, msg); err != nil {
+				return err
+			}
+		}
+	}
 
-			if err != nil {
-				if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-					// Проверяем, не отменен ли основной контекст
-					select {
-					case <-ctx.Done():
-						return nil
-					default:
-						continue // Таймаут чтения, продолжаем
-					}
+	// Ошибка воркера останавливает чтение и остальные воркеры
+	workCtx, halt := context.WithCancelCause(ctx)
+	defer halt(nil)
+
+	tracker := newOffsetTracker()
+	workers := make([]chan *trackedMessage, c.concurrency)
+	var wg sync.WaitGroup
+	for i := range workers {
+		workers[i] = make(chan *trackedMessage)
+		wg.Add(1)
+		go func(messages <-chan *trackedMessage) {
+			defer wg.Done()
+			for tm := range messages {
+				action, err := c.runHandler(workCtx, tm.msg)
+				if err != nil {
+					halt(err)
 				}
-				log.Error().Err(err).Msg("Error reading message")
+				tracker.complete(tm, action, func(msg kafka.Message) {
+					c.commit(workCtx, msg)
+				})
+			}
+		}(workers[i])
+	}
+	stopWorkers := func() {
+		for _, w := range workers {
+			close(w)
+		}
+		wg.Wait()
+	}
+
+	for {
+		msg, ok := c.fetchMessage(workCtx)
+		if ok {
+			tm := tracker.track(msg)
+			select {
+			case workers[messageWorker(msg, len(workers))] <- tm:
 				continue
+			case <-workCtx.Done():
 			}
+		}
+
+		stopWorkers()
+		if ctx.Err() == nil {
+			// Остановка вызвана ошибкой воркера, а не внешним контекстом
+			return context.Cause(workCtx)
+		}
+		return nil
+	}
+}
 
-			// Метрика получения сообщения
-			c.metrics.IncMessagesReceived(c.topic, msg.Partition)
+// messageWorker возвращает номер воркера для сообщения: по ключу, а для
+// сообщений без ключа - по партиции
+func messageWorker(msg kafka.Message, workers int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(msg.Topic))
+	if len(msg.Key) > 0 {
+		_, _ = h.Write(msg.Key)
+		return int(h.Sum32() % uint32(workers))
+	}
+	return int((h.Sum32() + uint32(msg.Partition)) % uint32(workers))
+}
 
-			if err := c.processMessage(ctx, msg); err != nil {
-				log.Error().
-					Err(err).
-					Str("topic", msg.Topic).
-					Int("partition", msg.Partition).
-					Int64("offset", msg.Offset).
-					Msg("Failed to process message")
+// fetchMessage читает следующее сообщение без коммита. Возвращает false,
+// когда ctx отменен.
+func (c *Consumer) fetchMessage(ctx context.Context) (kafka.Message, bool) {
+	for {
+		select {
+		case <-ctx.Done():
+			c.logger.Info().Msg("Context cancelled, stopping message processing")
+			return kafka.Message{}, false
+		default:
+		}
 
-				// Метрика ошибки обработки
-				c.metrics.IncMessagesProcessed(c.topic, "error")
+		// Устанавливаем таймаут для чтения сообщений
+		readCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		msg, err := c.currentReader().FetchMessage(readCtx)
+		cancel()
 
-				// В случае ошибки всё равно коммитим, так как retry/DLQ уже обработаны
-				if commitErr := c.reader.CommitMessages(ctx, msg); commitErr != nil {
-					log.Error().Err(commitErr).Msg("Failed to commit message after processing error")
-				}
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				continue // Таймаут чтения или отмена, проверяем контекст в начале цикла
+			}
+			if isMessageTooLarge(err) {
+				c.handleOversized(ctx, err)
 				continue
 			}
+			c.logger.Error().Err(err).Msg("Error reading message")
+			continue
+		}
 
-			// Метрика успешной обработки
-			c.metrics.IncMessagesProcessed(c.topic, "success")
+		// Метрика получения сообщения
+		c.metrics.IncMessagesReceived(msg.Topic, msg.Partition)
+		return msg, true
+	}
+}
 
-			if err := c.reader.CommitMessages(ctx, msg); err != nil {
-				log.Error().Err(err).Msg("Failed to commit message")
-				continue
-			}
+// handleMessage обрабатывает сообщение и коммитит его offset после
+// обработки. Ошибка означает, что consumer должен остановиться.
+func (c *Consumer) handleMessage(ctx context.Context, msg kafka.Message) error {
+	action, err := c.runHandler(ctx, msg)
+	if action == ackCommit {
+		c.commit(ctx, msg)
+	}
+	return err
+}
+
+// runHandler обрабатывает сообщение и решает, коммитить ли его offset, в
+// соответствии с AckMode. Ошибка возвращается, когда сообщение нельзя
+// коммитить, а следующие за ним нельзя обрабатывать (on-success-only).
+func (c *Consumer) runHandler(ctx context.Context, msg kafka.Message) (ackAction, error) {
+	if c.limitRate(ctx, msg) {
+		return ackCommit, nil
+	}
+	if ctx.Err() != nil {
+		return ackHold, nil
+	}
+
+	handlerCtx, acked := contextWithAck(ctx)
+	err := c.processMessage(handlerCtx, msg)
+	if err != nil {
+		c.logger.Error().
+			Err(err).
+			Str("topic", msg.Topic).
+			Int("partition", msg.Partition).
+			Int64("offset", msg.Offset).
+			Msg("Failed to process message")
+
+		// Метрика ошибки обработки
+		c.metrics.IncMessagesProcessed(msg.Topic, "error")
+	} else {
+		// Метрика успешной обработки
+		c.metrics.IncMessagesProcessed(msg.Topic, "success")
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		// Обработка прервана остановкой consumer: не коммитим, сообщение
+		// будет доставлено повторно
+		return ackHold, nil
+	case c.ackMode == AckModeManual:
+		if acked.Load() {
+			return ackCommit, nil
 		}
+		return ackSkip, nil
+	case err == nil:
+		return ackCommit, nil
+	case c.ackMode == AckModeOnSuccessOnly:
+		// Consumer group не позволяет перечитать сообщение, поэтому
+		// останавливаемся: после перезапуска чтение начнется с него
+		return ackHold, fmt.Errorf("%w: %s/%d offset %d: %w",
+			ErrMessageNotCommitted, msg.Topic, msg.Partition, msg.Offset, err)
+	default:
+		// auto: коммитим и при ошибке, так как retry/DLQ уже обработаны
+		return ackCommit, nil
+	}
+}
+
+// commit коммитит offset сообщения
+func (c *Consumer) commit(ctx context.Context, msg kafka.Message) {
+	if err := c.currentReader().CommitMessages(ctx, msg); err != nil {
+		c.logger.Error().
+			Err(err).
+			Str("topic", msg.Topic).
+			Int("partition", msg.Partition).
+			Int64("offset", msg.Offset).
+			Msg("Failed to commit message")
 	}
 }
 
 func (c *Consumer) processMessage(ctx context.Context, msg kafka.Message) error {
-	start := time.Now()
-	defer func() {
-		// Записываем время обработки
-		c.metrics.RecordProcessingTime(c.topic, time.Since(start))
-	}()
+	// Топик сообщения и контекст трассировки передаем обработчику через контекст
+	ctx = transport.ContextWithTopic(ctx, msg.Topic)
+	ctx = extractTrace(ctx, c.tracing, msg)
 
-	// Если есть retry processor, используем его
+	// Если есть retry processor, используем его. Время обработки он
+	// записывает сам для каждой попытки.
 	if c.retryProcessor != nil {
 		return c.retryProcessor.ProcessWithRetry(ctx, msg, c.handler)
 	}
 
+	start := time.Now()
+	defer func() {
+		// Записываем время обработки
+		c.metrics.RecordProcessingTime(msg.Topic, time.Since(start))
+	}()
+
 	// Иначе используем простую обработку
-	var envelope transport.Envelope
-	if err := json.Unmarshal(msg.Value, &envelope); err != nil {
-		return fmt.Errorf("failed to unmarshal message: %w", err)
+	envelope, err := decodeEnvelope(msg)
+	if err != nil {
+		return err
 	}
 
 	if err := c.handler.Handle(ctx, envelope); err != nil {
@@ -0,0 +1,238 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"gitlab.com/zynero/shared/transport"
+)
+
+func TestReaderConfig(t *testing.T) {
+	cfg := Config{
+		Brokers: []string{"localhost:9092"},
+		Consumer: ConsumerConfig{
+			GroupID:           "group",
+			StartOffset:       "latest",
+			HeartbeatInterval: 2 * time.Second,
+			SessionTimeout:    20 * time.Second,
+			RebalanceTimeout:  40 * time.Second,
+		},
+	}
+
+	rc := readerConfig(cfg)
+	if rc.StartOffset != kafka.LastOffset {
+		t.Errorf("StartOffset = %d, want %d", rc.StartOffset, kafka.LastOffset)
+	}
+	if rc.HeartbeatInterval != 2*time.Second {
+		t.Errorf("HeartbeatInterval = %v, want 2s", rc.HeartbeatInterval)
+	}
+	if rc.SessionTimeout != 20*time.Second {
+		t.Errorf("SessionTimeout = %v, want 20s", rc.SessionTimeout)
+	}
+	if rc.RebalanceTimeout != 40*time.Second {
+		t.Errorf("RebalanceTimeout = %v, want 40s", rc.RebalanceTimeout)
+	}
+
+	cfg.Consumer.StartOffset = "earliest"
+	if got := readerConfig(cfg).StartOffset; got != kafka.FirstOffset {
+		t.Errorf("earliest: StartOffset = %d, want %d", got, kafka.FirstOffset)
+	}
+}
+
+func TestPartitionLag(t *testing.T) {
+	tests := []struct {
+		name                        string
+		first, watermark, committed int64
+		want                        int64
+	}{
+		{"behind", 0, 100, 60, 40},
+		{"caught up", 0, 100, 100, 0},
+		{"nothing committed", 10, 100, -1, 90},
+	}
+
+	for _, tt := range tests {
+		if got := partitionLag(tt.first, tt.watermark, tt.committed); got != tt.want {
+			t.Errorf("%s: partitionLag() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOffsetTracker(t *testing.T) {
+	tracker := newOffsetTracker()
+	var committed []int64
+	commitFn := func(msg kafka.Message) { committed = append(committed, msg.Offset) }
+
+	var tracked []*trackedMessage
+	for _, offset := range []int64{10, 11, 13, 14} {
+		tracked = append(tracked, tracker.track(kafka.Message{Topic: "orders", Partition: 0, Offset: offset}))
+	}
+	other := tracker.track(kafka.Message{Topic: "orders", Partition: 1, Offset: 5})
+
+	// Later messages finish first: nothing is committed until offset 10 is done
+	tracker.complete(tracked[2], ackCommit, commitFn)
+	tracker.complete(tracked[1], ackSkip, commitFn)
+	if len(committed) != 0 {
+		t.Fatalf("committed %v before the first message finished", committed)
+	}
+
+	tracker.complete(other, ackCommit, commitFn)
+	tracker.complete(tracked[0], ackCommit, commitFn)
+	if want := []int64{5, 13}; !reflect.DeepEqual(committed, want) {
+		t.Fatalf("committed = %v, want %v", committed, want)
+	}
+
+	// An interrupted message blocks further commits of its partition
+	next := tracker.track(kafka.Message{Topic: "orders", Partition: 0, Offset: 15})
+	tracker.complete(tracked[3], ackHold, commitFn)
+	tracker.complete(next, ackCommit, commitFn)
+	if want := []int64{5, 13}; !reflect.DeepEqual(committed, want) {
+		t.Errorf("committed = %v after interrupted message, want %v", committed, want)
+	}
+}
+
+func TestMessageWorker(t *testing.T) {
+	a := kafka.Message{Topic: "orders", Partition: 0, Key: []byte("order-1")}
+	b := kafka.Message{Topic: "orders", Partition: 0, Key: []byte("order-1"), Offset: 7}
+	if messageWorker(a, 8) != messageWorker(b, 8) {
+		t.Error("messages with the same key were assigned to different workers")
+	}
+
+	workers := make(map[int]bool)
+	for i := range 32 {
+		msg := kafka.Message{Topic: "orders", Partition: 0, Key: []byte{byte(i)}}
+		workers[messageWorker(msg, 8)] = true
+	}
+	if len(workers) < 2 {
+		t.Error("keys of one partition were not spread across workers")
+	}
+}
+
+type ackingHandler struct {
+	commit bool
+	err    error
+}
+
+func (h *ackingHandler) Handle(ctx context.Context, _ transport.Envelope) error {
+	if h.commit && !Commit(ctx) {
+		return errors.New("commit outside of consumer handler context")
+	}
+	return h.err
+}
+
+func TestRunHandlerAckModes(t *testing.T) {
+	failure := errors.New("boom")
+	tests := []struct {
+		name       string
+		ackMode    string
+		handlerErr error
+		commit     bool
+		want       ackAction
+		wantErr    bool
+	}{
+		{"auto success", "", nil, false, ackCommit, false},
+		{"auto failure", AckModeAuto, failure, false, ackCommit, false},
+		{"on-success-only success", AckModeOnSuccessOnly, nil, false, ackCommit, false},
+		{"on-success-only failure", AckModeOnSuccessOnly, failure, false, ackHold, true},
+		{"manual acknowledged", AckModeManual, failure, true, ackCommit, false},
+		{"manual not acknowledged", AckModeManual, nil, false, ackSkip, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Consumer{
+				handler: &ackingHandler{commit: tt.commit, err: tt.handlerErr},
+				metrics: &transport.NoOpMetrics{},
+				logger:  defaultLogger(),
+				ackMode: tt.ackMode,
+			}
+			msg := kafka.Message{
+				Topic: "orders",
+				Value: []byte(`{"event_id":"1","event_type":"order.created","payload":{}}`),
+			}
+
+			action, err := c.runHandler(context.Background(), msg)
+			if action != tt.want {
+				t.Errorf("action = %d, want %d", action, tt.want)
+			}
+			if tt.wantErr != errors.Is(err, ErrMessageNotCommitted) {
+				t.Errorf("error = %v, want ErrMessageNotCommitted: %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if Commit(context.Background()) {
+		t.Error("Commit() outside a consumer handler = true")
+	}
+}
+
+func TestOversizedPosition(t *testing.T) {
+	tooLarge := kafka.MessageTooLargeError{
+		Message: kafka.Message{Topic: "orders", Partition: 2, Offset: 41},
+	}
+	if !isMessageTooLarge(tooLarge) {
+		t.Error("isMessageTooLarge(MessageTooLargeError) = false")
+	}
+	if !isMessageTooLarge(fmt.Errorf("fetch: %w", kafka.MessageSizeTooLarge)) {
+		t.Error("isMessageTooLarge(wrapped MessageSizeTooLarge) = false")
+	}
+	if isMessageTooLarge(errors.New("connection reset")) {
+		t.Error("isMessageTooLarge(other error) = true")
+	}
+
+	pos, ok := oversizedPosition(tooLarge, kafka.ReaderStats{})
+	if !ok || pos.Topic != "orders" || pos.Partition != 2 || pos.Offset != 41 {
+		t.Errorf("position from error = %+v, %v", pos, ok)
+	}
+
+	stats := kafka.ReaderStats{Topic: "payments", Partition: "1", Offset: 7}
+	pos, ok = oversizedPosition(kafka.MessageSizeTooLarge, stats)
+	if !ok || pos.Topic != "payments" || pos.Partition != 1 || pos.Offset != 7 {
+		t.Errorf("position from stats = %+v, %v", pos, ok)
+	}
+
+	if _, ok := oversizedPosition(kafka.MessageSizeTooLarge, kafka.ReaderStats{Topic: "payments", Offset: -1}); ok {
+		t.Error("unknown position reported as known")
+	}
+}
+
+func TestKeyLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newKeyLimiter(RateLimitConfig{
+		Rate:      2,
+		Overrides: map[string]float64{"vip": 0, "slow": 1},
+	})
+	l.now = func() time.Time { return now }
+
+	// Burst по умолчанию равен скорости
+	for i := 0; i < 2; i++ {
+		if d := l.reserve("tenant"); d != 0 {
+			t.Fatalf("reserve #%d delay = %v, want 0", i, d)
+		}
+	}
+	if d := l.reserve("tenant"); d != 500*time.Millisecond {
+		t.Errorf("reserve over the limit delay = %v, want 500ms", d)
+	}
+	if l.allow("tenant") {
+		t.Error("allow() over the limit = true")
+	}
+
+	// Другие ключи не зависят от исчерпанного
+	if !l.allow("slow") || l.allow("slow") {
+		t.Error("override rate of 1 not applied")
+	}
+	for i := 0; i < 10; i++ {
+		if !l.allow("vip") {
+			t.Fatal("key with zero override rate was limited")
+		}
+	}
+
+	now = now.Add(time.Second)
+	if !l.allow("tenant") {
+		t.Error("allow() after refill = false")
+	}
+}
@@ -0,0 +1,194 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	json "github.com/bytedance/sonic"
+	platformlogger "gitlab.com/zynero/shared/logger"
+	"gitlab.com/zynero/shared/transport"
+)
+
+// ReplayOptions настройки DLQReplayer
+type ReplayOptions struct {
+	// MaxMessages останавливает переотправку после указанного числа
+	// сообщений, 0 - без ограничения
+	MaxMessages int
+	// DryRun только логирует сообщения, которые были бы переотправлены.
+	// Offset группы replayer при этом коммитятся, поэтому для пробного
+	// запуска используйте отдельный GroupID.
+	DryRun bool
+	// StripDLQHeaders удаляет заголовки, добавленные retry processor, чтобы
+	// счетчик повторов переотправленного сообщения начинался заново
+	StripDLQHeaders bool
+}
+
+// ReplayStats итоги переотправки
+type ReplayStats struct {
+	Replayed int
+	Skipped  int
+	Failed   int
+}
+
+// DLQReplayer читает топик DLQ и переотправляет каждое сообщение в топик из
+// его заголовка OriginalTopicHeader. Сообщения без заголовка пропускаются.
+// При ошибке публикации или достижении MaxMessages replayer останавливается,
+// не коммитя текущее сообщение, поэтому оно остается в DLQ до следующего
+// запуска.
+type DLQReplayer struct {
+	consumer *Consumer
+	producer transport.HeaderProducer
+	opts     ReplayOptions
+	strip    map[string]bool
+	logger   *platformlogger.Logger
+
+	mu    sync.Mutex
+	stats ReplayStats
+}
+
+// NewDLQReplayer создает replayer, читающий dlqTopic в consumer group
+// cfg.Consumer.GroupID и публикующий через producer. Сообщения
+// обрабатываются по одному с синхронным коммитом, повторы и DLQ самого
+// replayer отключены. Возвращает ошибку, если не удалось создать consumer,
+// например при некорректной настройке SASL/TLS.
+func NewDLQReplayer(cfg Config, dlqTopic string, producer transport.HeaderProducer, opts ReplayOptions) (*DLQReplayer, error) {
+	r := &DLQReplayer{
+		producer: producer,
+		opts:     opts,
+		strip:    dlqHeaders(cfg.Reliability),
+		logger:   defaultLogger(),
+	}
+
+	consumerCfg := cfg
+	consumerCfg.Reliability = ReliabilityConfig{}
+	consumerCfg.Consumer.Concurrency = 1
+	consumerCfg.Consumer.CommitStrategy = CommitStrategySync
+	consumer, err := NewConsumer(consumerCfg, dlqTopic, r)
+	if err != nil {
+		return nil, err
+	}
+	r.consumer = consumer
+	return r, nil
+}
+
+// dlqHeaders возвращает набор заголовков, добавляемых RetryProcessor
+func dlqHeaders(cfg ReliabilityConfig) map[string]bool {
+	defaults := GetDefaultReliabilityConfig()
+	headers := map[string]bool{
+		ErrorCodeHeader:             true,
+		OriginalTopicHeader:         true,
+		OriginalPartitionHeader:     true,
+		OriginalOffsetHeader:        true,
+		defaults.DLQRetryHeader:     true,
+		defaults.DLQErrorHeader:     true,
+		defaults.DLQTimestampHeader: true,
+	}
+	for _, h := range []string{cfg.DLQRetryHeader, cfg.DLQErrorHeader, cfg.DLQTimestampHeader} {
+		if h != "" {
+			headers[h] = true
+		}
+	}
+	return headers
+}
+
+// SetLogger устанавливает логгер replayer и его consumer
+func (r *DLQReplayer) SetLogger(l *platformlogger.Logger) {
+	r.logger = l
+	r.consumer.SetLogger(l)
+}
+
+// Run переотправляет сообщения до отмены ctx, достижения MaxMessages или
+// ошибки публикации, затем логирует и возвращает итоги.
+func (r *DLQReplayer) Run(ctx context.Context) (ReplayStats, error) {
+	err := r.consumer.Run(ctx)
+	stats := r.Stats()
+
+	r.logger.Info().
+		Int("replayed", stats.Replayed).
+		Int("skipped", stats.Skipped).
+		Int("failed", stats.Failed).
+		Bool("dry_run", r.opts.DryRun).
+		Msg("DLQ replay finished")
+
+	if stats.Failed > 0 && err == nil {
+		err = fmt.Errorf("dlq replay stopped after %d failed publishes", stats.Failed)
+	}
+	return stats, err
+}
+
+// Stats возвращает счетчики текущего запуска
+func (r *DLQReplayer) Stats() ReplayStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+// Close освобождает consumer
+func (r *DLQReplayer) Close() error {
+	return r.consumer.Close()
+}
+
+// Handle переотправляет одно сообщение DLQ. Реализует transport.Handler.
+func (r *DLQReplayer) Handle(ctx context.Context, envelope transport.Envelope) error {
+	r.mu.Lock()
+	limitReached := r.opts.MaxMessages > 0 && r.stats.Replayed >= r.opts.MaxMessages
+	r.mu.Unlock()
+	if limitReached {
+		return r.halt(ctx)
+	}
+
+	topic := envelope.Headers[OriginalTopicHeader]
+	if topic == "" {
+		r.logger.Warn().Str("event_id", envelope.EventID).Msg("DLQ message has no original topic, skipping")
+		r.count(func(s *ReplayStats) { s.Skipped++ })
+		return nil
+	}
+
+	headers := envelope.Headers
+	if r.opts.StripDLQHeaders {
+		headers = make(map[string]string, len(envelope.Headers))
+		for k, v := range envelope.Headers {
+			if !r.strip[k] {
+				headers[k] = v
+			}
+		}
+	}
+	if len(headers) == 0 {
+		headers = nil
+	}
+	envelope.Headers = headers
+
+	if r.opts.DryRun {
+		r.logger.Info().Str("event_id", envelope.EventID).Str("topic", topic).Msg("Dry run: would replay DLQ message")
+		r.count(func(s *ReplayStats) { s.Replayed++ })
+		return nil
+	}
+
+	value, err := json.Marshal(envelope)
+	if err == nil {
+		err = r.producer.PublishWithHeaders(ctx, topic, envelope.EventID, value, headers)
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Str("event_id", envelope.EventID).Str("topic", topic).Msg("Failed to replay DLQ message")
+		r.count(func(s *ReplayStats) { s.Failed++ })
+		return r.halt(ctx)
+	}
+
+	r.count(func(s *ReplayStats) { s.Replayed++ })
+	return nil
+}
+
+func (r *DLQReplayer) count(update func(*ReplayStats)) {
+	r.mu.Lock()
+	update(&r.stats)
+	r.mu.Unlock()
+}
+
+// halt останавливает consumer и ожидает отмены его контекста, поэтому
+// текущее сообщение не коммитится и остается в DLQ
+func (r *DLQReplayer) halt(ctx context.Context) error {
+	r.consumer.Stop()
+	<-ctx.Done()
+	return ctx.Err()
+}
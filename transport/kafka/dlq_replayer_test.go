@@ -0,0 +1,89 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	json "github.com/bytedance/sonic"
+	"gitlab.com/zynero/shared/transport"
+)
+
+type headerRecordingProducer struct {
+	recordingProducer
+	headers []map[string]string
+	values  [][]byte
+}
+
+func (p *headerRecordingProducer) PublishWithHeaders(_ context.Context, topic, _ string, value []byte, headers map[string]string) error {
+	p.topics = append(p.topics, topic)
+	p.values = append(p.values, value)
+	p.headers = append(p.headers, headers)
+	return nil
+}
+
+func TestDLQReplayerHandle(t *testing.T) {
+	producer := &headerRecordingProducer{}
+	r := &DLQReplayer{
+		producer: producer,
+		opts:     ReplayOptions{StripDLQHeaders: true},
+		strip:    dlqHeaders(GetDefaultReliabilityConfig()),
+		logger:   defaultLogger(),
+	}
+
+	ctx := context.Background()
+	err := r.Handle(ctx, transport.Envelope{
+		EventID: "1",
+		Headers: map[string]string{
+			OriginalTopicHeader: "orders",
+			"x-retry-count":     "3",
+			"x-error-message":   "boom",
+			"traceparent":       "00-abc",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if err := r.Handle(ctx, transport.Envelope{EventID: "2"}); err != nil {
+		t.Fatalf("Handle() without original topic error = %v", err)
+	}
+
+	if len(producer.topics) != 1 || producer.topics[0] != "orders" {
+		t.Fatalf("published to %v, want [orders]", producer.topics)
+	}
+	headers := producer.headers[0]
+	if len(headers) != 1 || headers["traceparent"] != "00-abc" {
+		t.Errorf("DLQ headers not stripped: %v", headers)
+	}
+	var replayed transport.Envelope
+	if err := json.Unmarshal(producer.values[0], &replayed); err != nil || replayed.EventID != "1" {
+		t.Errorf("unexpected replayed envelope %+v, err %v", replayed, err)
+	}
+
+	if got := r.Stats(); got.Replayed != 1 || got.Skipped != 1 {
+		t.Errorf("Stats() = %+v, want 1 replayed and 1 skipped", got)
+	}
+}
+
+func TestDLQReplayerDryRun(t *testing.T) {
+	producer := &headerRecordingProducer{}
+	r := &DLQReplayer{
+		producer: producer,
+		opts:     ReplayOptions{DryRun: true},
+		strip:    dlqHeaders(ReliabilityConfig{}),
+		logger:   defaultLogger(),
+	}
+
+	err := r.Handle(context.Background(), transport.Envelope{
+		EventID: "1",
+		Headers: map[string]string{OriginalTopicHeader: "orders"},
+	})
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if len(producer.topics) != 0 {
+		t.Errorf("dry run published %v", producer.topics)
+	}
+	if got := r.Stats(); got.Replayed != 1 {
+		t.Errorf("Stats() = %+v, want 1 replayed", got)
+	}
+}
@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+)
+
+// ErrorCategoryHeader заголовок DLQ с категорией ошибки
+const ErrorCategoryHeader = "x-error-category"
+
+// Категории ошибок в заголовке ErrorCategoryHeader и метке category метрики
+// dlq_messages_total. Набор фиксирован, чтобы ограничить число временных рядов.
+const (
+	// ErrorCategoryTimeout истекший дедлайн или сетевой таймаут
+	ErrorCategoryTimeout = "timeout"
+	// ErrorCategoryValidation некорректное сообщение или ошибка, которую
+	// обработчик пометил как неповторяемую
+	ErrorCategoryValidation = "validation"
+	// ErrorCategoryCircuitOpen сообщение отклонено разомкнутым circuit breaker
+	ErrorCategoryCircuitOpen = "circuit_open"
+	// ErrorCategoryOversized сообщение больше MaxBytes consumer
+	ErrorCategoryOversized = "oversized"
+	// ErrorCategoryUnknown остальные ошибки, обычно повторяемые ошибки,
+	// исчерпавшие повторы
+	ErrorCategoryUnknown = "unknown"
+)
+
+// ClassifyError относит ошибку обработки к одной из категорий ErrorCategory
+func ClassifyError(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		return ErrorCategoryCircuitOpen
+	case errors.Is(err, ErrMessageTooLarge):
+		return ErrorCategoryOversized
+	case errors.Is(err, ErrMalformedMessage):
+		return ErrorCategoryValidation
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorCategoryTimeout
+	case err != nil && !isRetryable(err):
+		return ErrorCategoryValidation
+	default:
+		return ErrorCategoryUnknown
+	}
+}
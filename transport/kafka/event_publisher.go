@@ -6,53 +6,93 @@ import (
 	"gitlab.com/zynero/shared/transport"
 	"time"
 
-	"github.com/rs/zerolog/log"
-
 	"github.com/google/uuid"
 )
 
 // KafkaEventPublisher реализует интерфейс Publisher для отправки событий в Kafka.
 type KafkaEventPublisher struct {
-	producer transport.Producer // Используем интерфейс Producer из pkg/transport
-	topic    string
+	producer      transport.Producer // Используем интерфейс Producer из pkg/transport
+	topic         string
+	tracing       TracingConfig
+	schemaVersion string
+	contentType   string
 }
 
 // NewKafkaEventPublisher создает новый экземпляр KafkaEventPublisher.
 func NewKafkaEventPublisher(p transport.Producer, topic string) *KafkaEventPublisher {
 	return &KafkaEventPublisher{
-		producer: p,
-		topic:    topic,
+		producer:      p,
+		topic:         topic,
+		schemaVersion: transport.DefaultSchemaVersion,
+		contentType:   "application/json",
 	}
 }
 
+// SetTracing включает передачу контекста трассировки в заголовках сообщений.
+func (kep *KafkaEventPublisher) SetTracing(cfg TracingConfig) {
+	kep.tracing = cfg
+}
+
+// SetSchemaVersion задает версию схемы, указываемую в публикуемых конвертах.
+func (kep *KafkaEventPublisher) SetSchemaVersion(version string) {
+	kep.schemaVersion = version
+}
+
 // Publish сериализует полезную нагрузку и отправляет ее в Kafka, обернув в Envelope.
 func (kep *KafkaEventPublisher) Publish(ctx context.Context, eventType string, eventID string, payload any) error {
+	return kep.PublishWithHeaders(ctx, eventType, eventID, payload, nil)
+}
+
+// PublishWithHeaders работает как Publish и дополнительно передает заголовки.
+// Заголовки сохраняются в Envelope и, если producer реализует
+// transport.HeaderProducer, передаются как заголовки сообщения Kafka.
+func (kep *KafkaEventPublisher) PublishWithHeaders(ctx context.Context, eventType string, eventID string, payload any, headers map[string]string) error {
+	return kep.publish(ctx, eventType, eventID, kep.schemaVersion, payload, headers)
+}
+
+// PublishVersion работает как Publish, но указывает в конверте версию схемы
+// version вместо заданной SetSchemaVersion. Позволяет публиковать новую версию
+// события, не переключая остальные события publisher'а.
+func (kep *KafkaEventPublisher) PublishVersion(ctx context.Context, eventType string, eventID string, version string, payload any) error {
+	return kep.publish(ctx, eventType, eventID, version, payload, nil)
+}
+
+func (kep *KafkaEventPublisher) publish(ctx context.Context, eventType string, eventID string, version string, payload any, headers map[string]string) error {
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		log.Error().Err(err).Msg("Error marshalling payload")
+		defaultLogger().Error().Err(err).Msg("Error marshalling payload")
 		return err // Ошибка маршалинга полезной нагрузки
 	}
 
+	// Контекст трассировки передаем в заголовках, если есть активный span
+	headers = injectTrace(ctx, kep.tracing, headers)
+
 	// Если eventID не предоставлен, генерируем новый UUID.
 	if eventID == "" {
 		eventID = uuid.NewString()
 	}
 
 	envelope := transport.Envelope{
-		EventID:    eventID,
-		EventType:  eventType,
-		OccurredAt: time.Now().UTC(), // Важно использовать UTC для консистентности
-		Payload:    payloadBytes,     // json.RawMessage, поэтому присваиваем напрямую
+		EventID:       eventID,
+		EventType:     eventType,
+		OccurredAt:    time.Now().UTC(), // Важно использовать UTC для консистентности
+		Payload:       payloadBytes,     // json.RawMessage, поэтому присваиваем напрямую
+		SchemaVersion: version,
+		ContentType:   kep.contentType,
+		Headers:       headers,
 	}
 
 	envelopeBytes, err := json.Marshal(envelope)
 	if err != nil {
-		log.Error().Err(err).Msg("Error marshalling event envelope") // Ошибка маршалинга конверта
+		defaultLogger().Error().Err(err).Msg("Error marshalling event envelope") // Ошибка маршалинга конверта
 		return err
 	}
 
 	// В качестве ключа Kafka используем EventID для обеспечения возможного упорядочивания
 	// или партиционирования по ID события, если это необходимо.
+	if hp, ok := kep.producer.(transport.HeaderProducer); ok && len(headers) > 0 {
+		return hp.PublishWithHeaders(ctx, kep.topic, envelope.EventID, envelopeBytes, headers)
+	}
 	return kep.producer.Publish(ctx, kep.topic, envelope.EventID, envelopeBytes)
 }
 
@@ -0,0 +1,156 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// DefaultLagInterval период обновления метрики отставания consumer по умолчанию
+const DefaultLagInterval = 30 * time.Second
+
+// lagReporter периодически вычисляет отставание consumer group как разницу
+// между high watermark партиции и закоммиченным offset группы. Запросы идут
+// напрямую в брокер, поэтому отставание видно и когда сообщений нет.
+type lagReporter struct {
+	client   *kafka.Client
+	groupID  string
+	topics   []string
+	interval time.Duration
+}
+
+// newLagReporter создает lagReporter. Без consumer group отставание не
+// вычисляется и возвращается nil.
+func newLagReporter(cfg Config, topics []string) (*lagReporter, error) {
+	if cfg.Consumer.GroupID == "" {
+		return nil, nil
+	}
+
+	lagTransport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := cfg.Consumer.LagInterval
+	if interval <= 0 {
+		interval = DefaultLagInterval
+	}
+
+	return &lagReporter{
+		client: &kafka.Client{
+			Addr:      kafka.TCP(cfg.Brokers...),
+			Timeout:   10 * time.Second,
+			Transport: lagTransport,
+		},
+		groupID:  cfg.Consumer.GroupID,
+		topics:   topics,
+		interval: interval,
+	}, nil
+}
+
+// run обновляет метрику отставания каждые interval до отмены ctx
+func (r *lagReporter) run(ctx context.Context, c *Consumer) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		lags, err := r.lag(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				c.mu.RLock()
+				logger := c.logger
+				c.mu.RUnlock()
+				logger.Warn().Err(err).Msg("Failed to compute consumer lag")
+			}
+			continue
+		}
+
+		c.mu.RLock()
+		metrics := c.metrics
+		c.mu.RUnlock()
+		for topic, partitions := range lags {
+			for partition, lag := range partitions {
+				metrics.SetConsumerLag(topic, partition, lag)
+			}
+		}
+	}
+}
+
+// lag возвращает отставание по топикам и партициям
+func (r *lagReporter) lag(ctx context.Context) (map[string]map[int]int64, error) {
+	meta, err := r.client.Metadata(ctx, &kafka.MetadataRequest{Topics: r.topics})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+
+	partitions := make(map[string][]int, len(meta.Topics))
+	offsets := make(map[string][]kafka.OffsetRequest, len(meta.Topics))
+	for _, topic := range meta.Topics {
+		if topic.Error != nil {
+			return nil, fmt.Errorf("failed to fetch metadata for topic %s: %w", topic.Name, topic.Error)
+		}
+		for _, p := range topic.Partitions {
+			partitions[topic.Name] = append(partitions[topic.Name], p.ID)
+			offsets[topic.Name] = append(offsets[topic.Name], kafka.FirstOffsetOf(p.ID), kafka.LastOffsetOf(p.ID))
+		}
+	}
+
+	committed, err := r.client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{
+		GroupID: r.groupID,
+		Topics:  partitions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch committed offsets: %w", err)
+	}
+	if committed.Error != nil {
+		return nil, fmt.Errorf("failed to fetch committed offsets: %w", committed.Error)
+	}
+
+	watermarks, err := r.client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: offsets})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offsets: %w", err)
+	}
+
+	result := make(map[string]map[int]int64, len(watermarks.Topics))
+	for topic, parts := range watermarks.Topics {
+		committedByPartition := make(map[int]int64, len(committed.Topics[topic]))
+		for _, p := range committed.Topics[topic] {
+			if p.Error == nil {
+				committedByPartition[p.Partition] = p.CommittedOffset
+			}
+		}
+
+		result[topic] = make(map[int]int64, len(parts))
+		for _, p := range parts {
+			if p.Error != nil {
+				continue
+			}
+			offset, ok := committedByPartition[p.Partition]
+			if !ok {
+				offset = -1
+			}
+			result[topic][p.Partition] = partitionLag(p.FirstOffset, p.LastOffset, offset)
+		}
+	}
+	return result, nil
+}
+
+// partitionLag вычисляет отставание партиции. Если группа еще ничего не
+// коммитила (offset < 0), отставанием считаются все сообщения партиции.
+func partitionLag(first, highWatermark, committed int64) int64 {
+	if committed < 0 {
+		committed = first
+	}
+	if lag := highWatermark - committed; lag > 0 {
+		return lag
+	}
+	return 0
+}
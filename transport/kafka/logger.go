@@ -0,0 +1,14 @@
+package kafka
+
+import (
+	platformlogger "gitlab.com/zynero/shared/logger"
+)
+
+// LoggerComponent is the value of the component field in kafka log entries.
+const LoggerComponent = "kafka"
+
+// defaultLogger returns the global logger tagged with the kafka component, so
+// entries carry the application's global fields.
+func defaultLogger() *platformlogger.Logger {
+	return platformlogger.GetGlobal().With().Str("component", LoggerComponent).Logger()
+}
@@ -5,9 +5,11 @@
 //   - messages_processed_total    {topic, status}
 //   - message_processing_duration_seconds {topic}
 //   - retry_attempts_total        {topic, attempt}
+//   - consumer_lag                {topic, partition}
 //   - messages_sent_total         {topic, status}
 //   - message_publish_duration_seconds {topic}
-//   - dlq_messages_total          {original_topic, dlq_topic}
+//   - dlq_messages_total          {original_topic, dlq_topic, category}
+//   - circuit_breaker_state       {topic} (0 closed, 1 half-open, 2 open)
 //   - active_consumers            no labels
 //   - active_producers            no labels
 //   - uptime_seconds              no labels
@@ -20,6 +22,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gitlab.com/zynero/shared/transport"
 )
 
 // KafkaMetrics provides a Prometheus metrics implementation used by the Kafka
@@ -30,6 +33,7 @@ type KafkaMetrics struct {
 	messagesProcessed *prometheus.CounterVec
 	processingTime    *prometheus.HistogramVec
 	retryAttempts     *prometheus.CounterVec
+	consumerLag       *prometheus.GaugeVec
 
 	// Producer metrics
 	messagesSent *prometheus.CounterVec
@@ -38,6 +42,9 @@ type KafkaMetrics struct {
 	// DLQ metrics
 	dlqMessages *prometheus.CounterVec
 
+	// Circuit breaker metrics
+	circuitBreakerState *prometheus.GaugeVec
+
 	// Common metrics
 	activeConsumers prometheus.Gauge
 	activeProducers prometheus.Gauge
@@ -49,11 +56,29 @@ type KafkaMetrics struct {
 	doneCh    chan struct{}
 }
 
-// NewKafkaMetrics creates a new metrics collector for the Kafka transport.
-func NewKafkaMetrics(serviceName string) *KafkaMetrics {
+var (
+	_ transport.DLQCategoryMetrics    = (*KafkaMetrics)(nil)
+	_ transport.CircuitBreakerMetrics = (*KafkaMetrics)(nil)
+)
+
+// NewDefaultKafkaMetrics creates Kafka transport metrics registered on the
+// default Prometheus registerer. Use NewKafkaMetrics with a dedicated registry
+// when several transports share a process or in tests.
+func NewDefaultKafkaMetrics(serviceName string) *KafkaMetrics {
+	return NewKafkaMetrics(prometheus.DefaultRegisterer, serviceName)
+}
+
+// NewKafkaMetrics creates a new metrics collector for the Kafka transport and
+// registers it on reg, typically the registry of the shared metrics package.
+// A nil reg uses the default Prometheus registerer.
+func NewKafkaMetrics(reg prometheus.Registerer, serviceName string) *KafkaMetrics {
 	if serviceName == "" {
 		serviceName = "kafka_transport"
 	}
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	factory := promauto.With(reg)
 
 	m := &KafkaMetrics{
 		startTime: time.Now(),
@@ -62,7 +87,7 @@ func NewKafkaMetrics(serviceName string) *KafkaMetrics {
 	}
 
 	// Consumer metrics
-	m.messagesReceived = promauto.NewCounterVec(
+	m.messagesReceived = factory.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: fmt.Sprintf("%s_messages_received_total", serviceName),
 			Help: "Total number of messages received from Kafka topics",
@@ -70,16 +95,16 @@ func NewKafkaMetrics(serviceName string) *KafkaMetrics {
 		[]string{"topic", "partition"},
 	)
 
-	m.messagesProcessed = promauto.NewCounterVec(
+	m.messagesProcessed = factory.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: fmt.Sprintf("%s_messages_processed_total", serviceName),
 			Help: "Total number of messages processed",
 		},
-		// status label has values: success, error, retry, dlq
+		// status label values are documented on transport.Metrics
 		[]string{"topic", "status"},
 	)
 
-	m.processingTime = promauto.NewHistogramVec(
+	m.processingTime = factory.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    fmt.Sprintf("%s_message_processing_duration_seconds", serviceName),
 			Help:    "Time spent processing messages",
@@ -88,7 +113,7 @@ func NewKafkaMetrics(serviceName string) *KafkaMetrics {
 		[]string{"topic"},
 	)
 
-	m.retryAttempts = promauto.NewCounterVec(
+	m.retryAttempts = factory.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: fmt.Sprintf("%s_retry_attempts_total", serviceName),
 			Help: "Total number of retry attempts",
@@ -96,8 +121,16 @@ func NewKafkaMetrics(serviceName string) *KafkaMetrics {
 		[]string{"topic", "attempt"},
 	)
 
+	m.consumerLag = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_consumer_lag", serviceName),
+			Help: "Consumer group lag in messages: high watermark minus committed offset",
+		},
+		[]string{"topic", "partition"},
+	)
+
 	// Producer metrics
-	m.messagesSent = promauto.NewCounterVec(
+	m.messagesSent = factory.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: fmt.Sprintf("%s_messages_sent_total", serviceName),
 			Help: "Total number of messages sent to Kafka topics",
@@ -106,7 +139,7 @@ func NewKafkaMetrics(serviceName string) *KafkaMetrics {
 		[]string{"topic", "status"},
 	)
 
-	m.publishTime = promauto.NewHistogramVec(
+	m.publishTime = factory.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    fmt.Sprintf("%s_message_publish_duration_seconds", serviceName),
 			Help:    "Time spent publishing messages",
@@ -116,30 +149,39 @@ func NewKafkaMetrics(serviceName string) *KafkaMetrics {
 	)
 
 	// DLQ metrics
-	m.dlqMessages = promauto.NewCounterVec(
+	m.dlqMessages = factory.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: fmt.Sprintf("%s_dlq_messages_total", serviceName),
 			Help: "Total number of messages sent to Dead Letter Queue",
 		},
-		[]string{"original_topic", "dlq_topic"},
+		[]string{"original_topic", "dlq_topic", "category"},
+	)
+
+	// Circuit breaker metrics
+	m.circuitBreakerState = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_circuit_breaker_state", serviceName),
+			Help: "Circuit breaker state: 0 closed, 1 half-open, 2 open",
+		},
+		[]string{"topic"},
 	)
 
 	// Common metrics
-	m.activeConsumers = promauto.NewGauge(
+	m.activeConsumers = factory.NewGauge(
 		prometheus.GaugeOpts{
 			Name: fmt.Sprintf("%s_active_consumers", serviceName),
 			Help: "Number of active consumers",
 		},
 	)
 
-	m.activeProducers = promauto.NewGauge(
+	m.activeProducers = factory.NewGauge(
 		prometheus.GaugeOpts{
 			Name: fmt.Sprintf("%s_active_producers", serviceName),
 			Help: "Number of active producers",
 		},
 	)
 
-	m.uptime = promauto.NewGauge(
+	m.uptime = factory.NewGauge(
 		prometheus.GaugeOpts{
 			Name: fmt.Sprintf("%s_uptime_seconds", serviceName),
 			Help: "Service uptime in seconds",
@@ -169,6 +211,10 @@ func (m *KafkaMetrics) IncRetryAttempts(topic string, attempt int) {
 	m.retryAttempts.WithLabelValues(topic, fmt.Sprintf("%d", attempt)).Inc()
 }
 
+func (m *KafkaMetrics) SetConsumerLag(topic string, partition int, lag int64) {
+	m.consumerLag.WithLabelValues(topic, fmt.Sprintf("%d", partition)).Set(float64(lag))
+}
+
 // Producer metrics
 func (m *KafkaMetrics) IncMessagesSent(topic string, status string) {
 	m.messagesSent.WithLabelValues(topic, status).Inc()
@@ -179,8 +225,19 @@ func (m *KafkaMetrics) RecordPublishTime(topic string, duration time.Duration) {
 }
 
 // DLQ metrics
+
+// IncDLQMessages counts a DLQ message with the unknown error category.
 func (m *KafkaMetrics) IncDLQMessages(originalTopic, dlqTopic string) {
-	m.dlqMessages.WithLabelValues(originalTopic, dlqTopic).Inc()
+	m.IncDLQMessagesWithCategory(originalTopic, dlqTopic, ErrorCategoryUnknown)
+}
+
+func (m *KafkaMetrics) IncDLQMessagesWithCategory(originalTopic, dlqTopic, category string) {
+	m.dlqMessages.WithLabelValues(originalTopic, dlqTopic, category).Inc()
+}
+
+// Circuit breaker metrics
+func (m *KafkaMetrics) SetCircuitBreakerState(topic string, state int) {
+	m.circuitBreakerState.WithLabelValues(topic).Set(float64(state))
 }
 
 // Common metrics
@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestNewKafkaMetricsSeparateRegistries(t *testing.T) {
+	first := prometheus.NewRegistry()
+	second := prometheus.NewRegistry()
+
+	// Identical names on separate registries must not panic
+	m1 := NewKafkaMetrics(first, "orders")
+	m2 := NewKafkaMetrics(second, "orders")
+	defer m1.Close()
+	defer m2.Close()
+
+	m1.IncMessagesSent("orders", "success")
+
+	families, err := first.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	found := false
+	for _, f := range families {
+		if f.GetName() == "orders_messages_sent_total" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("orders_messages_sent_total not registered on the custom registry")
+	}
+}
+
+func TestIncDLQMessagesCategory(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewKafkaMetrics(reg, "orders")
+	defer m.Close()
+
+	m.IncDLQMessages("orders", "orders.dlq")
+	m.IncDLQMessagesWithCategory("orders", "orders.dlq", ErrorCategoryTimeout)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	categories := map[string]float64{}
+	for _, f := range families {
+		if f.GetName() != "orders_dlq_messages_total" {
+			continue
+		}
+		for _, metric := range f.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "category" {
+					categories[label.GetValue()] = metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	// Without a category the message is counted as unknown
+	if categories[ErrorCategoryUnknown] != 1 || categories[ErrorCategoryTimeout] != 1 {
+		t.Errorf("dlq_messages_total by category = %v, want unknown and timeout once", categories)
+	}
+}
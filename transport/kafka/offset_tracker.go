@@ -0,0 +1,82 @@
+package kafka
+
+import (
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// topicPartition идентифицирует партицию топика
+type topicPartition struct {
+	topic     string
+	partition int
+}
+
+// trackedMessage сообщение, ожидающее завершения обработки
+type trackedMessage struct {
+	msg    kafka.Message
+	done   bool
+	action ackAction
+}
+
+// offsetTracker упорядочивает коммиты при параллельной обработке. Сообщения
+// одной партиции с разными ключами завершаются не по порядку, а коммит offset
+// в Kafka подтверждает и все предыдущие сообщения. Поэтому коммитится только
+// непрерывный префикс завершенных сообщений партиции в порядке чтения.
+type offsetTracker struct {
+	mu      sync.Mutex
+	pending map[topicPartition][]*trackedMessage
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{
+		pending: make(map[topicPartition][]*trackedMessage),
+	}
+}
+
+// track регистрирует прочитанное сообщение. Вызывается в порядке чтения.
+func (t *offsetTracker) track(msg kafka.Message) *trackedMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tm := &trackedMessage{msg: msg}
+	tp := topicPartition{topic: msg.Topic, partition: msg.Partition}
+	t.pending[tp] = append(t.pending[tp], tm)
+	return tm
+}
+
+// complete отмечает сообщение обработанным и вызывает commitFn для последнего
+// сообщения с ackCommit в непрерывном завершенном префиксе партиции.
+// Сообщение с ackHold блокирует коммиты партиции, чтобы оно и следующие за
+// ним были доставлены повторно. commitFn вызывается под блокировкой, поэтому
+// коммиты не обгоняют друг друга.
+func (t *offsetTracker) complete(tm *trackedMessage, action ackAction, commitFn func(kafka.Message)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tm.done = true
+	tm.action = action
+
+	tp := topicPartition{topic: tm.msg.Topic, partition: tm.msg.Partition}
+	queue := t.pending[tp]
+	n := 0
+	last := -1
+	for n < len(queue) && queue[n].done && queue[n].action != ackHold {
+		if queue[n].action == ackCommit {
+			last = n
+		}
+		n++
+	}
+	if n == 0 {
+		return
+	}
+
+	if n == len(queue) {
+		delete(t.pending, tp)
+	} else {
+		t.pending[tp] = queue[n:]
+	}
+	if last >= 0 {
+		commitFn(queue[last].msg)
+	}
+}
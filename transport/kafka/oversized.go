@@ -0,0 +1,96 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// ErrMessageTooLarge означает, что сообщение не помещается в MaxBytes consumer
+var ErrMessageTooLarge = errors.New("message exceeds consumer max bytes")
+
+// isMessageTooLarge проверяет, что ошибка чтения вызвана сообщением больше MaxBytes
+func isMessageTooLarge(err error) bool {
+	var tooLarge kafka.MessageTooLargeError
+	return errors.Is(err, kafka.MessageSizeTooLarge) || errors.As(err, &tooLarge)
+}
+
+// oversizedPosition возвращает топик, партицию и offset сообщения, на котором
+// остановилось чтение. Позиция берется из ошибки, а если ее там нет - из
+// статистики reader. false означает, что позиция неизвестна.
+func oversizedPosition(err error, stats kafka.ReaderStats) (kafka.Message, bool) {
+	var tooLarge kafka.MessageTooLargeError
+	if errors.As(err, &tooLarge) && tooLarge.Message.Topic != "" {
+		msg := tooLarge.Message
+		return kafka.Message{Topic: msg.Topic, Partition: msg.Partition, Offset: msg.Offset}, true
+	}
+
+	partition, perr := strconv.Atoi(stats.Partition)
+	if perr != nil || stats.Topic == "" || stats.Offset < 0 {
+		return kafka.Message{}, false
+	}
+	return kafka.Message{Topic: stats.Topic, Partition: partition, Offset: stats.Offset}, true
+}
+
+// handleOversized обрабатывает ошибку чтения сообщения больше MaxBytes. Без
+// SkipOversized только логирует позицию: чтение партиции будет повторяться.
+// С SkipOversized отправляет в DLQ запись без тела, коммитит offset
+// сообщения и пересоздает reader, чтобы чтение продолжилось со следующего.
+func (c *Consumer) handleOversized(ctx context.Context, err error) {
+	reader := c.currentReader()
+	pos, known := oversizedPosition(err, reader.Stats())
+
+	event := c.logger.Error().Err(err).Int("max_bytes", c.readerCfg.MaxBytes)
+	if known {
+		event = event.
+			Str("topic", pos.Topic).
+			Int("partition", pos.Partition).
+			Int64("offset", pos.Offset)
+	}
+	if !c.skipOversized || !known {
+		event.Msg("Message exceeds max bytes, increase max_bytes or enable skip_oversized")
+		return
+	}
+	event.Msg("Message exceeds max bytes, skipping")
+	c.metrics.IncMessagesProcessed(pos.Topic, "oversized")
+
+	if c.retryProcessor != nil {
+		tooLarge := fmt.Errorf("%w (%d bytes): %w", ErrMessageTooLarge, c.readerCfg.MaxBytes, err)
+		// Ошибка DLQ уже залогирована и не должна снова блокировать партицию
+		_ = c.retryProcessor.sendToDLQ(ctx, pos, tooLarge, 0)
+	}
+
+	// Коммит сообщения сохраняет offset следующего за ним
+	if err := reader.CommitMessages(ctx, pos); err != nil {
+		c.logger.Error().
+			Err(err).
+			Str("topic", pos.Topic).
+			Int("partition", pos.Partition).
+			Int64("offset", pos.Offset).
+			Msg("Failed to commit oversized message")
+		return
+	}
+	c.resetReader(reader)
+}
+
+// currentReader возвращает текущий reader consumer
+func (c *Consumer) currentReader() *kafka.Reader {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.reader
+}
+
+// resetReader заменяет reader новым, который начнет чтение с закоммиченных
+// offset. Незакоммиченные сообщения старого reader будут доставлены повторно.
+func (c *Consumer) resetReader(old *kafka.Reader) {
+	c.mu.Lock()
+	c.reader = kafka.NewReader(c.readerCfg)
+	c.mu.Unlock()
+
+	if err := old.Close(); err != nil {
+		c.logger.Error().Err(err).Msg("Error closing Kafka reader")
+	}
+}
@@ -2,13 +2,13 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
-	"github.com/rs/zerolog/log"
 	"github.com/segmentio/kafka-go"
-	"github.com/segmentio/kafka-go/sasl/scram"
+	platformlogger "gitlab.com/zynero/shared/logger"
 	"gitlab.com/zynero/shared/transport"
 )
 
@@ -16,36 +16,111 @@ type KafkaProducer struct {
 	writer       *kafka.Writer
 	defaultTopic string
 	metrics      transport.Metrics
+	logger       *platformlogger.Logger
+	completion   func(messages []kafka.Message, err error)
+	async        bool
+	closeTimeout time.Duration
+	inflight     inflight
 	mu           sync.RWMutex
 	closed       bool
 }
 
+// inflight считает публикации, которые еще пишутся в Kafka. В режиме Async
+// сообщение считается до вызова Completion для его батча.
+type inflight struct {
+	mu   sync.Mutex
+	n    int
+	idle chan struct{}
+}
+
+func (f *inflight) add() {
+	f.addN(1)
+}
+
+func (f *inflight) done() {
+	f.doneN(1)
+}
+
+func (f *inflight) addN(n int) {
+	if n <= 0 {
+		return
+	}
+	f.mu.Lock()
+	if f.n == 0 {
+		f.idle = make(chan struct{})
+	}
+	f.n += n
+	f.mu.Unlock()
+}
+
+func (f *inflight) doneN(n int) {
+	if n <= 0 {
+		return
+	}
+	f.mu.Lock()
+	f.n -= n
+	if f.n == 0 {
+		close(f.idle)
+	}
+	f.mu.Unlock()
+}
+
+// wait ожидает завершения всех публикаций или отмены ctx
+func (f *inflight) wait(ctx context.Context) error {
+	f.mu.Lock()
+	if f.n == 0 {
+		f.mu.Unlock()
+		return nil
+	}
+	idle := f.idle
+	f.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // NewProducer создает нового KafkaProducer на основе предоставленной конфигурации.
+// Подключение к брокерам происходит лениво при первой публикации.
 func NewProducer(cfg Config) (*KafkaProducer, error) {
-	sharedTransport := &kafka.Transport{}
-	if cfg.SASL.Enabled {
-		mechanism, err := scram.Mechanism(scram.SHA512, cfg.SASL.Username, cfg.SASL.Password)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create SASL mechanism: %w", err)
-		}
-		sharedTransport.SASL = mechanism
+	if err := cfg.Producer.Validate(); err != nil {
+		return nil, err
+	}
+
+	sharedTransport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	writer := &kafka.Writer{
 		Addr:         kafka.TCP(cfg.Brokers...),
-		Balancer:     &kafka.Hash{},
+		Balancer:     partitionBalancer{cfg.Producer.GetBalancer()},
 		Transport:    sharedTransport,
 		BatchSize:    cfg.Producer.BatchSize,
 		BatchTimeout: cfg.Producer.BatchTimeout,
-		RequiredAcks: kafka.RequiredAcks(cfg.Producer.RequiredAcks),
+		RequiredAcks: cfg.Producer.GetRequiredAcks(),
 		Compression:  cfg.Producer.GetCompressionCodec(),
+		WriteTimeout: cfg.Producer.WriteTimeout,
+		Async:        cfg.Producer.Async,
+	}
+
+	closeTimeout := cfg.Producer.CloseTimeout
+	if closeTimeout <= 0 {
+		closeTimeout = DefaultCloseTimeout
 	}
 
 	producer := &KafkaProducer{
 		writer:       writer,
 		defaultTopic: cfg.Producer.Topic,
 		metrics:      &transport.NoOpMetrics{}, // По умолчанию no-op метрики
+		logger:       defaultLogger(),
+		async:        cfg.Producer.Async,
+		closeTimeout: closeTimeout,
 	}
+	writer.Completion = producer.complete
 
 	// Обновляем метрики активных producer
 	producer.metrics.SetActiveProducers(1)
@@ -53,6 +128,37 @@ func NewProducer(cfg Config) (*KafkaProducer, error) {
 	return producer, nil
 }
 
+// NewProducerContext создает KafkaProducer и проверяет доступность хотя бы
+// одного брокера. Проверка прерывается при отмене ctx.
+func NewProducerContext(ctx context.Context, cfg Config) (*KafkaProducer, error) {
+	if err := cfg.Producer.Validate(); err != nil {
+		return nil, err
+	}
+
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var dialErr error
+	for _, broker := range cfg.Brokers {
+		conn, err := dialer.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			_ = conn.Close()
+			dialErr = nil
+			break
+		}
+		dialErr = fmt.Errorf("failed to connect to broker %s: %w", broker, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if dialErr != nil {
+		return nil, dialErr
+	}
+
+	return NewProducer(cfg)
+}
+
 // SetMetrics устанавливает интерфейс метрик
 func (p *KafkaProducer) SetMetrics(metrics transport.Metrics) {
 	p.mu.Lock()
@@ -60,7 +166,87 @@ func (p *KafkaProducer) SetMetrics(metrics transport.Metrics) {
 	p.metrics = metrics
 }
 
+// SetLogger устанавливает логгер producer. По умолчанию используется
+// глобальный логгер с полем component=kafka.
+func (p *KafkaProducer) SetLogger(l *platformlogger.Logger) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.logger = l
+}
+
+// SetCompletion устанавливает callback, вызываемый после записи каждого
+// батча в Kafka. err != nil означает, что сообщения батча не доставлены.
+// В режиме Async это единственный способ узнать результат доставки.
+func (p *KafkaProducer) SetCompletion(fn func(messages []kafka.Message, err error)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.completion = fn
+}
+
+// complete вызывается kafka-go после записи батча. В режиме Async метрики
+// результата записываются здесь, так как WriteMessages не дожидается доставки.
+func (p *KafkaProducer) complete(messages []kafka.Message, err error) {
+	p.mu.RLock()
+	metrics := p.metrics
+	logger := p.logger
+	completion := p.completion
+	p.mu.RUnlock()
+
+	if p.async {
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		for _, msg := range messages {
+			metrics.IncMessagesSent(msg.Topic, status)
+		}
+		// Сообщения батча больше не ожидают доставки
+		defer p.inflight.doneN(len(messages))
+	}
+
+	if err != nil && len(messages) > 0 {
+		logger.Error().
+			Err(err).
+			Str("topic", messages[0].Topic).
+			Int("partition", messages[0].Partition).
+			Int("messages", len(messages)).
+			Msg("Failed to deliver messages to Kafka")
+	}
+
+	if completion != nil {
+		completion(messages, err)
+	}
+}
+
 func (p *KafkaProducer) Publish(ctx context.Context, topic, key string, value []byte) error {
+	return p.PublishWithHeaders(ctx, topic, key, value, nil)
+}
+
+// PublishWithHeaders публикует сообщение с заголовками Kafka
+func (p *KafkaProducer) PublishWithHeaders(ctx context.Context, topic, key string, value []byte, headers map[string]string) error {
+	return p.publish(ctx, topic, kafka.Message{
+		Key:     []byte(key),
+		Value:   value,
+		Headers: kafkaHeaders(headers),
+	})
+}
+
+// PublishToPartition публикует сообщение в указанную партицию, минуя
+// балансировщик. Несуществующая партиция приводит к ошибке записи.
+func (p *KafkaProducer) PublishToPartition(ctx context.Context, topic string, partition int, key string, value []byte, headers map[string]string) error {
+	if partition < 0 {
+		return fmt.Errorf("invalid partition %d", partition)
+	}
+	return p.publish(ctx, topic, kafka.Message{
+		Key:        []byte(key),
+		Value:      value,
+		Headers:    kafkaHeaders(headers),
+		WriterData: explicitPartition(partition),
+	})
+}
+
+// publish записывает одно сообщение и обновляет метрики
+func (p *KafkaProducer) publish(ctx context.Context, topic string, msg kafka.Message) error {
 	start := time.Now()
 
 	p.mu.RLock()
@@ -75,6 +261,7 @@ func (p *KafkaProducer) Publish(ctx context.Context, topic, key string, value []
 	}
 
 	metrics := p.metrics
+	p.inflight.add()
 	p.mu.RUnlock()
 
 	// Измеряем время публикации
@@ -82,11 +269,13 @@ func (p *KafkaProducer) Publish(ctx context.Context, topic, key string, value []
 		metrics.RecordPublishTime(t, time.Since(start))
 	}()
 
-	err := p.writer.WriteMessages(ctx, kafka.Message{
-		Topic: t,
-		Key:   []byte(key),
-		Value: value,
-	})
+	msg.Topic = t
+	err := p.writer.WriteMessages(ctx, msg)
+	if p.async && err == nil {
+		// Результат доставки записывает complete, он же снимает сообщение с учета
+		return nil
+	}
+	p.inflight.done()
 
 	// Записываем метрики результата
 	if err != nil {
@@ -98,27 +287,164 @@ func (p *KafkaProducer) Publish(ctx context.Context, topic, key string, value []
 	return nil
 }
 
-// Close выполняет graceful shutdown producer
+// explicitPartition передается в kafka.Message.WriterData, чтобы
+// partitionBalancer выбрал партицию, указанную в PublishToPartition
+type explicitPartition int
+
+// partitionBalancer использует явно указанную партицию, а для остальных
+// сообщений делегирует выбор настроенному балансировщику
+type partitionBalancer struct {
+	kafka.Balancer
+}
+
+func (b partitionBalancer) Balance(msg kafka.Message, partitions ...int) int {
+	if partition, ok := msg.WriterData.(explicitPartition); ok {
+		return int(partition)
+	}
+	return b.Balancer.Balance(msg, partitions...)
+}
+
+// kafkaHeaders преобразует заголовки в формат kafka-go
+func kafkaHeaders(headers map[string]string) []kafka.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	result := make([]kafka.Header, 0, len(headers))
+	for k, v := range headers {
+		result = append(result, kafka.Header{Key: k, Value: []byte(v)})
+	}
+	return result
+}
+
+// Message представляет сообщение для пакетной публикации
+type Message struct {
+	Key   string
+	Value []byte
+}
+
+// PublishBatch публикует сообщения одним вызовом WriteMessages. Если topic
+// пустой, используется топик по умолчанию. Метрики результата записываются
+// для каждого сообщения.
+func (p *KafkaProducer) PublishBatch(ctx context.Context, topic string, messages []Message) error {
+	start := time.Now()
+
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return fmt.Errorf("producer is closed")
+	}
+
+	t := p.defaultTopic
+	if topic != "" {
+		t = topic
+	}
+
+	metrics := p.metrics
+	if len(messages) == 0 {
+		p.mu.RUnlock()
+		return nil
+	}
+	p.inflight.addN(len(messages))
+	p.mu.RUnlock()
+
+	defer func() {
+		metrics.RecordPublishTime(t, time.Since(start))
+	}()
+
+	batch := make([]kafka.Message, len(messages))
+	for i, m := range messages {
+		batch[i] = kafka.Message{
+			Topic: t,
+			Key:   []byte(m.Key),
+			Value: m.Value,
+		}
+	}
+
+	err := p.writer.WriteMessages(ctx, batch...)
+	if p.async && err == nil {
+		// Результат доставки записывает complete, он же снимает сообщения с учета
+		return nil
+	}
+	p.inflight.doneN(len(batch))
+
+	// При частичной ошибке kafka-go возвращает ошибку для каждого сообщения
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) && len(writeErrs) == len(batch) {
+		for _, writeErr := range writeErrs {
+			if writeErr != nil {
+				metrics.IncMessagesSent(t, "error")
+			} else {
+				metrics.IncMessagesSent(t, "success")
+			}
+		}
+		return err
+	}
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	for range batch {
+		metrics.IncMessagesSent(t, status)
+	}
+	return err
+}
+
+// Flush ожидает завершения публикаций, начатых до вызова, или отмены ctx.
+// В режиме Async ожидается доставка буферизованных сообщений, то есть вызов
+// Completion для их батчей. Новые публикации во время ожидания также учитываются.
+func (p *KafkaProducer) Flush(ctx context.Context) error {
+	return p.inflight.wait(ctx)
+}
+
+// Close выполняет graceful shutdown producer. Ожидание отправки сообщений
+// ограничено ProducerConfig.CloseTimeout: по его истечении возвращается
+// ошибка, оборачивающая context.DeadlineExceeded, а writer закрывается в фоне.
 func (p *KafkaProducer) Close() error {
 	p.mu.Lock()
-	defer p.mu.Unlock()
-
 	if p.closed {
+		p.mu.Unlock()
 		return nil
 	}
+	// Новые публикации отклоняются с момента начала закрытия
+	p.closed = true
+	metrics := p.metrics
+	logger := p.logger
+	// Блокировку не удерживаем во время закрытия writer: он вызывает complete
+	// для буферизованных сообщений
+	p.mu.Unlock()
 
-	log.Info().Msg("Closing producer...")
+	logger.Info().Msg("Closing producer...")
 
 	// Обновляем метрики перед закрытием
-	p.metrics.SetActiveProducers(0)
+	metrics.SetActiveProducers(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.closeTimeout)
+	defer cancel()
+
+	if err := p.Flush(ctx); err != nil {
+		logger.Error().Err(err).Dur("timeout", p.closeTimeout).Msg("Timed out waiting for in-flight messages")
+		go p.writer.Close()
+		return fmt.Errorf("producer close timed out after %s: %w", p.closeTimeout, err)
+	}
 
 	// Закрываем writer, это дождется отправки всех буферизованных сообщений
-	if err := p.writer.Close(); err != nil {
-		log.Error().Err(err).Msg("Error closing Kafka writer")
-		return fmt.Errorf("failed to close writer: %w", err)
+	closed := make(chan error, 1)
+	go func() {
+		closed <- p.writer.Close()
+	}()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			logger.Error().Err(err).Msg("Error closing Kafka writer")
+			return fmt.Errorf("failed to close writer: %w", err)
+		}
+	case <-ctx.Done():
+		logger.Error().Dur("timeout", p.closeTimeout).Msg("Timed out closing Kafka writer")
+		return fmt.Errorf("producer close timed out after %s: %w", p.closeTimeout, ctx.Err())
 	}
 
-	p.closed = true
-	log.Info().Msg("Producer closed successfully")
+	logger.Info().Msg("Producer closed successfully")
 	return nil
 }
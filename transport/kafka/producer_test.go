@@ -0,0 +1,201 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	platformlogger "gitlab.com/zynero/shared/logger"
+	"gitlab.com/zynero/shared/transport"
+)
+
+func TestProducerCloseTimeout(t *testing.T) {
+	cfg := Config{
+		Brokers:  []string{"localhost:9092"},
+		Producer: ProducerConfig{RequiredAcks: 1, CloseTimeout: 20 * time.Millisecond},
+	}
+	p, err := NewProducer(cfg)
+	if err != nil {
+		t.Fatalf("NewProducer() error = %v", err)
+	}
+
+	// Simulate a publish that never completes
+	p.inflight.add()
+
+	start := time.Now()
+	err = p.Close()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Close() took %v, want it bounded by CloseTimeout", elapsed)
+	}
+	if err := p.Publish(context.Background(), "orders", "", nil); err == nil {
+		t.Error("Publish() after Close should fail")
+	}
+}
+
+func TestProducerFlush(t *testing.T) {
+	p, err := NewProducer(Config{Brokers: []string{"localhost:9092"}, Producer: ProducerConfig{RequiredAcks: 1}})
+	if err != nil {
+		t.Fatalf("NewProducer() error = %v", err)
+	}
+	defer p.Close()
+
+	if err := p.Flush(context.Background()); err != nil {
+		t.Errorf("Flush() without in-flight messages error = %v", err)
+	}
+
+	p.inflight.add()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		p.inflight.done()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := p.Flush(ctx); err != nil {
+		t.Errorf("Flush() error = %v", err)
+	}
+}
+
+func TestProducerFlushAsync(t *testing.T) {
+	cfg := Config{
+		Brokers:  []string{"localhost:9092"},
+		Producer: ProducerConfig{RequiredAcks: 1, Async: true},
+	}
+	p, err := NewProducer(cfg)
+	if err != nil {
+		t.Fatalf("NewProducer() error = %v", err)
+	}
+	defer p.Close()
+
+	// Two messages buffered by the writer, delivered in separate batches
+	batch := []kafka.Message{{Topic: "orders"}, {Topic: "orders"}}
+	p.inflight.addN(len(batch))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Flush() before delivery error = %v, want deadline exceeded", err)
+	}
+
+	p.writer.Completion(batch[:1], nil)
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Flush() after partial delivery error = %v, want deadline exceeded", err)
+	}
+
+	go p.writer.Completion(batch[1:], errors.New("broker unavailable"))
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := p.Flush(ctx); err != nil {
+		t.Errorf("Flush() after delivery error = %v", err)
+	}
+}
+
+func TestPartitionBalancer(t *testing.T) {
+	tests := []struct {
+		balancer string
+		want     kafka.Balancer
+	}{
+		{"", &kafka.Hash{}},
+		{"round-robin", &kafka.RoundRobin{}},
+		{"leastbytes", &kafka.LeastBytes{}},
+		{"murmur2", &kafka.Murmur2Balancer{}},
+	}
+	for _, tt := range tests {
+		got := (&ProducerConfig{Balancer: tt.balancer}).GetBalancer()
+		if reflect.TypeOf(got) != reflect.TypeOf(tt.want) {
+			t.Errorf("GetBalancer(%q) = %T, want %T", tt.balancer, got, tt.want)
+		}
+	}
+
+	b := partitionBalancer{&kafka.Murmur2Balancer{}}
+	partitions := []int{0, 1, 2, 3, 4, 5}
+	msg := kafka.Message{Key: []byte("order-42")}
+	if got, want := b.Balance(msg, partitions...), (&kafka.Murmur2Balancer{}).Balance(msg, partitions...); got != want {
+		t.Errorf("Balance() = %d, want configured balancer result %d", got, want)
+	}
+
+	msg.WriterData = explicitPartition(4)
+	if got := b.Balance(msg, partitions...); got != 4 {
+		t.Errorf("Balance() with explicit partition = %d, want 4", got)
+	}
+}
+
+type sentMetrics struct {
+	transport.NoOpMetrics
+	statuses []string
+}
+
+func (m *sentMetrics) IncMessagesSent(_ string, status string) {
+	m.statuses = append(m.statuses, status)
+}
+
+func TestProducerCompletion(t *testing.T) {
+	cfg := Config{
+		Brokers:  []string{"localhost:9092"},
+		Producer: ProducerConfig{RequiredAcks: 1, Async: true, WriteTimeout: time.Second},
+	}
+	p, err := NewProducer(cfg)
+	if err != nil {
+		t.Fatalf("NewProducer() error = %v", err)
+	}
+	defer p.Close()
+
+	if p.writer.WriteTimeout != time.Second || !p.writer.Async {
+		t.Errorf("writer WriteTimeout = %v, Async = %v", p.writer.WriteTimeout, p.writer.Async)
+	}
+
+	metrics := &sentMetrics{}
+	p.SetMetrics(metrics)
+	var delivered []error
+	p.SetCompletion(func(messages []kafka.Message, err error) {
+		delivered = append(delivered, err)
+	})
+
+	// Messages buffered by WriteMessages stay in flight until completion
+	p.inflight.addN(3)
+	batch := []kafka.Message{{Topic: "orders"}, {Topic: "orders"}}
+	p.writer.Completion(batch, nil)
+	p.writer.Completion(batch[:1], errors.New("broker unavailable"))
+
+	want := []string{"success", "success", "error"}
+	if !reflect.DeepEqual(metrics.statuses, want) {
+		t.Errorf("sent statuses = %v, want %v", metrics.statuses, want)
+	}
+	if len(delivered) != 2 || delivered[0] != nil || delivered[1] == nil {
+		t.Errorf("completion errors = %v", delivered)
+	}
+}
+
+func TestProducerLogger(t *testing.T) {
+	var buf bytes.Buffer
+	global := platformlogger.GetGlobal()
+	platformlogger.SetGlobal(global.Output(&buf))
+	defer platformlogger.SetGlobal(global)
+
+	p, err := NewProducer(Config{Brokers: []string{"localhost:9092"}, Producer: ProducerConfig{RequiredAcks: 1}})
+	if err != nil {
+		t.Fatalf("NewProducer() error = %v", err)
+	}
+	defer p.Close()
+
+	p.writer.Completion([]kafka.Message{{Topic: "orders"}}, errors.New("broker unavailable"))
+	if output := buf.String(); !strings.Contains(output, `"component":"kafka"`) || !strings.Contains(output, "Failed to deliver") {
+		t.Errorf("default logger output = %s, want kafka component entry", output)
+	}
+
+	var custom bytes.Buffer
+	p.SetLogger(global.Output(&custom))
+	p.writer.Completion([]kafka.Message{{Topic: "orders"}}, errors.New("broker unavailable"))
+	if !strings.Contains(custom.String(), "Failed to deliver") {
+		t.Errorf("custom logger output = %s, want delivery error", custom.String())
+	}
+}
@@ -0,0 +1,174 @@
+package kafka
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// maxIdleBuckets ограничивает число хранимых bucket: при превышении
+// удаляются заполненные, то есть давно не использованные ключи
+const maxIdleBuckets = 10000
+
+// tokenBucket хранит токены одного ключа. Токены могут уходить в минус:
+// так резервируется ожидание для следующих сообщений ключа.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// keyLimiter ограничивает скорость обработки сообщений по ключу
+type keyLimiter struct {
+	cfg     RateLimitConfig
+	now     func() time.Time
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+func newKeyLimiter(cfg RateLimitConfig) *keyLimiter {
+	return &keyLimiter{
+		cfg:     cfg,
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// limits возвращает скорость и burst для ключа. Нулевая скорость - без лимита.
+func (l *keyLimiter) limits(key string) (float64, float64) {
+	rate := l.cfg.Rate
+	if override, ok := l.cfg.Overrides[key]; ok {
+		rate = override
+	}
+	burst := float64(l.cfg.Burst)
+	if burst <= 0 {
+		burst = math.Max(1, math.Ceil(rate))
+	}
+	return rate, burst
+}
+
+// bucket возвращает bucket ключа с начисленными с прошлого вызова токенами.
+// Вызывается под l.mu.
+func (l *keyLimiter) bucket(key string, rate, burst float64) *tokenBucket {
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.evictIdle(now)
+		}
+		b = &tokenBucket{tokens: burst, last: now}
+		l.buckets[key] = b
+		return b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	return b
+}
+
+// evictIdle удаляет bucket, которые успели заполниться. Вызывается под l.mu.
+func (l *keyLimiter) evictIdle(now time.Time) {
+	for key, b := range l.buckets {
+		rate, burst := l.limits(key)
+		if b.tokens+now.Sub(b.last).Seconds()*rate >= burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// reserve забирает токен ключа и возвращает, сколько нужно подождать перед
+// обработкой сообщения
+func (l *keyLimiter) reserve(key string) time.Duration {
+	rate, burst := l.limits(key)
+	if rate <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(key, rate, burst)
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / rate * float64(time.Second))
+}
+
+// allow забирает токен ключа, только если он есть
+func (l *keyLimiter) allow(key string) bool {
+	rate, burst := l.limits(key)
+	if rate <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(key, rate, burst)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// limitRate применяет лимит скорости ключа сообщения. Возвращает true, если
+// сообщение отложено в топик парковки и обрабатывать его не нужно. Сообщения
+// без ключа не ограничиваются.
+func (c *Consumer) limitRate(ctx context.Context, msg kafka.Message) bool {
+	if c.limiter == nil || len(msg.Key) == 0 {
+		return false
+	}
+	key := string(msg.Key)
+
+	if c.rateLimitProducer != nil {
+		if c.limiter.allow(key) {
+			return false
+		}
+		err := c.parkRateLimited(ctx, msg)
+		if err == nil {
+			return true
+		}
+		c.logger.Error().
+			Err(err).
+			Str("topic", msg.Topic).
+			Int("partition", msg.Partition).
+			Int64("offset", msg.Offset).
+			Msg("Failed to park rate limited message, delaying it")
+	}
+
+	// Ожидание блокирует только воркер этого ключа
+	if delay := c.limiter.reserve(key); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+	return false
+}
+
+// parkRateLimited публикует сообщение в топик парковки с заголовками исходной
+// позиции
+func (c *Consumer) parkRateLimited(ctx context.Context, msg kafka.Message) error {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+3)
+	headers = append(headers, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: OriginalTopicHeader, Value: []byte(msg.Topic)},
+		kafka.Header{Key: OriginalPartitionHeader, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: OriginalOffsetHeader, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+	)
+
+	err := c.rateLimitProducer.publish(ctx, c.rateLimitTopic, kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+	if err != nil {
+		return err
+	}
+	c.metrics.IncMessagesProcessed(msg.Topic, "rate_limited")
+	return nil
+}
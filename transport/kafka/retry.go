@@ -2,13 +2,15 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	json "github.com/bytedance/sonic"
-	"github.com/rs/zerolog/log"
 	"github.com/segmentio/kafka-go"
+	platformlogger "gitlab.com/zynero/shared/logger"
 	"gitlab.com/zynero/shared/transport"
 )
 
@@ -27,6 +29,13 @@ func (e *RetryableError) Unwrap() error {
 	return e.Err
 }
 
+// IsRetryable reports whether the error may be retried. Together with Unwrap
+// it lets code checking transport.RetryableError semantics treat both error
+// kinds the same way through isRetryable and retryAfter.
+func (e *RetryableError) IsRetryable() bool {
+	return e.Retryable
+}
+
 // NewRetryableError creates a new RetryableError instance.
 func NewRetryableError(err error, retryable bool) *RetryableError {
 	return &RetryableError{
@@ -35,22 +44,82 @@ func NewRetryableError(err error, retryable bool) *RetryableError {
 	}
 }
 
+// ErrorCodeHeader is the DLQ header carrying transport.HandlerError code.
+const ErrorCodeHeader = "x-error-code"
+
+// DLQ headers describing where a message was originally consumed from.
+const (
+	OriginalTopicHeader     = "x-original-topic"
+	OriginalPartitionHeader = "x-original-partition"
+	OriginalOffsetHeader    = "x-original-offset"
+)
+
+// isRetryable reports whether err should be retried. It understands
+// RetryableError as well as transport.HandlerError and other errors
+// implementing transport.RetryableError.
+func isRetryable(err error) bool {
+	var retryableErr *RetryableError
+	if errors.As(err, &retryableErr) {
+		return retryableErr.Retryable
+	}
+	return transport.IsRetryableError(err)
+}
+
+// retryAfter returns the delay requested by err, or zero when it does not
+// request one.
+func retryAfter(err error) time.Duration {
+	var retryableErr *RetryableError
+	if errors.As(err, &retryableErr) {
+		return retryableErr.RetryAfter
+	}
+	var transportErr transport.RetryableError
+	if errors.As(err, &transportErr) {
+		return transportErr.RetryAfter()
+	}
+	return 0
+}
+
 // RetryProcessor handles retry logic for messages.
 type RetryProcessor struct {
 	config   ReliabilityConfig
 	producer transport.Producer
-	dlqTopic string
 	metrics  transport.Metrics
+	logger   *platformlogger.Logger
+	breaker  *CircuitBreaker // nil when the circuit breaker is disabled
+
+	admin   topicCreator // creates per-topic DLQs, nil disables creation
+	ensured sync.Map     // DLQ topics already created
+}
+
+// topicCreator creates a topic unless it exists; implemented by Admin.
+type topicCreator interface {
+	EnsureTopic(ctx context.Context, name string, partitions, replication int) error
 }
 
+// ErrCircuitOpen is reported for messages sent to the DLQ without calling the
+// handler because the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// ErrMaxRetriesExceeded is reported for messages discarded because their
+// retry count reached ReliabilityConfig.MaxTotalRetries.
+var ErrMaxRetriesExceeded = errors.New("max total retries exceeded")
+
+// ErrMalformedMessage is reported for messages whose body is not a valid
+// transport.Envelope.
+var ErrMalformedMessage = errors.New("failed to unmarshal message")
+
 // NewRetryProcessor creates a new processor for retries.
 func NewRetryProcessor(config ReliabilityConfig, producer transport.Producer) *RetryProcessor {
-	return &RetryProcessor{
+	rp := &RetryProcessor{
 		config:   config,
 		producer: producer,
-		dlqTopic: config.DLQTopic,
 		metrics:  &transport.NoOpMetrics{}, // no-op metrics by default
+		logger:   defaultLogger(),
+	}
+	if config.CircuitBreakerConfig.Enabled {
+		rp.breaker = NewCircuitBreaker(config.CircuitBreakerConfig, nil)
 	}
+	return rp
 }
 
 // SetMetrics sets the metrics implementation.
@@ -58,23 +127,71 @@ func (rp *RetryProcessor) SetMetrics(metrics transport.Metrics) {
 	rp.metrics = metrics
 }
 
+// SetAdmin sets the Admin used to create DLQ topics with the suffix naming
+// strategy. Without it the topics must exist or be auto-created by the brokers.
+func (rp *RetryProcessor) SetAdmin(admin *Admin) {
+	if admin == nil {
+		rp.admin = nil
+		return
+	}
+	rp.admin = admin
+}
+
+// SetLogger sets the logger of the processor and its circuit breaker. The
+// global logger with component=kafka is used by default.
+func (rp *RetryProcessor) SetLogger(l *platformlogger.Logger) {
+	rp.logger = l
+	if rp.breaker != nil {
+		rp.breaker.SetLogger(l)
+	}
+}
+
 // ProcessWithRetry processes a message with retry logic.
 func (rp *RetryProcessor) ProcessWithRetry(ctx context.Context, msg kafka.Message, handler transport.Handler) error {
 	envelope, err := rp.parseMessage(msg)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to parse message")
+		rp.logger.Error().Err(err).Msg("Failed to parse message")
 		rp.metrics.IncMessagesProcessed(msg.Topic, "parse_error")
 		return rp.sendToDLQ(ctx, msg, err, -1)
 	}
 
 	retryCount := rp.getRetryCount(msg)
 
-	for attempt := 0; attempt <= rp.config.RetryCount; attempt++ {
-		err = handler.Handle(ctx, *envelope)
+	// A redelivered message that already used up its retry budget is not
+	// handled again
+	if rp.retryCeilingReached(retryCount) {
+		return rp.parkMessage(ctx, msg, fmt.Errorf("retry count %d reached the limit of %d",
+			retryCount, rp.config.MaxTotalRetries), retryCount)
+	}
+	maxRetries := rp.config.RetryCount
+	if rp.config.MaxTotalRetries > 0 {
+		maxRetries = min(maxRetries, rp.config.MaxTotalRetries-retryCount)
+	}
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		// While the circuit is open the handler is not called
+		if rp.breaker != nil && !rp.breaker.Allow() {
+			rp.recordBreakerState(msg.Topic)
+			rp.logger.Warn().
+				Str("event_id", envelope.EventID).
+				Msg("Circuit breaker open, sending to DLQ")
+			rp.metrics.IncMessagesProcessed(msg.Topic, "circuit_open")
+			circuitErr := ErrCircuitOpen
+			if err != nil {
+				circuitErr = fmt.Errorf("%w: %w", ErrCircuitOpen, err)
+			}
+			return rp.sendToDLQ(ctx, msg, circuitErr, retryCount+attempt)
+		}
+
+		err = rp.handle(ctx, msg.Topic, handler, *envelope)
+		if rp.breaker != nil {
+			rp.breaker.Record(err == nil)
+			rp.recordBreakerState(msg.Topic)
+		}
 		if err == nil {
 			// Successful processing
 			if attempt > 0 {
-				log.Info().
+				rp.logger.Info().
 					Str("event_id", envelope.EventID).
 					Int("retry_count", attempt).
 					Msg("Message processed successfully after retry")
@@ -89,18 +206,21 @@ func (rp *RetryProcessor) ProcessWithRetry(ctx context.Context, msg kafka.Messag
 		}
 
 		// Check whether we should retry
-		if retryableErr, ok := err.(*RetryableError); ok && !retryableErr.Retryable {
-			log.Error().
+		if !isRetryable(err) {
+			rp.logger.Error().
 				Err(err).
 				Str("event_id", envelope.EventID).
 				Msg("Non-retryable error, sending to DLQ")
 			rp.metrics.IncMessagesProcessed(msg.Topic, "non_retryable")
-			return rp.sendToDLQ(ctx, msg, err, retryCount+attempt)
+			return rp.fail(ctx, msg, err, retryCount+attempt)
 		}
 
-		if attempt < rp.config.RetryCount {
+		if attempt < maxRetries {
 			backoff := rp.config.GetRetryBackoffWithJitter(attempt)
-			log.Warn().
+			if delay := retryAfter(err); delay > 0 {
+				backoff = delay
+			}
+			rp.logger.Warn().
 				Err(err).
 				Str("event_id", envelope.EventID).
 				Int("attempt", attempt+1).
@@ -120,23 +240,121 @@ func (rp *RetryProcessor) ProcessWithRetry(ctx context.Context, msg kafka.Messag
 	}
 
 	// All retry attempts exhausted
-	log.Error().
+	rp.logger.Error().
 		Err(err).
 		Str("event_id", envelope.EventID).
-		Int("total_retries", rp.config.RetryCount).
+		Int("total_retries", maxRetries).
 		Msg("All retry attempts exhausted, sending to DLQ")
 
 	rp.metrics.IncMessagesProcessed(msg.Topic, "retry_exhausted")
-	return rp.sendToDLQ(ctx, msg, err, retryCount+rp.config.RetryCount)
+	return rp.fail(ctx, msg, err, retryCount+maxRetries)
+}
+
+// fail sends a message that failed processing to the DLQ, or parks it once
+// totalRetries reached MaxTotalRetries.
+func (rp *RetryProcessor) fail(ctx context.Context, msg kafka.Message, processingErr error, totalRetries int) error {
+	if rp.retryCeilingReached(totalRetries) {
+		return rp.parkMessage(ctx, msg, processingErr, totalRetries)
+	}
+	return rp.sendToDLQ(ctx, msg, processingErr, totalRetries)
+}
+
+// retryCeilingReached reports whether totalRetries reached MaxTotalRetries.
+func (rp *RetryProcessor) retryCeilingReached(totalRetries int) bool {
+	return rp.config.MaxTotalRetries > 0 && totalRetries >= rp.config.MaxTotalRetries
+}
+
+// parkMessage permanently removes a message that reached MaxTotalRetries from
+// the retry cycle: it is published to the parking topic, or discarded when no
+// parking topic is configured.
+func (rp *RetryProcessor) parkMessage(ctx context.Context, originalMsg kafka.Message, processingErr error, totalRetries int) error {
+	rp.metrics.IncMessagesProcessed(originalMsg.Topic, "permanently_failed")
+
+	if rp.config.ParkingTopic == "" {
+		rp.logger.Error().
+			Err(processingErr).
+			Str("original_topic", originalMsg.Topic).
+			Int("partition", originalMsg.Partition).
+			Int64("offset", originalMsg.Offset).
+			Int("total_retries", totalRetries).
+			Int("max_total_retries", rp.config.MaxTotalRetries).
+			Msg("Max total retries exceeded, DISCARDING message permanently")
+		return fmt.Errorf("%w: %w", ErrMaxRetriesExceeded, processingErr)
+	}
+
+	parkMsg := kafka.Message{
+		Topic:   rp.config.ParkingTopic,
+		Key:     originalMsg.Key,
+		Value:   originalMsg.Value,
+		Headers: rp.createDLQHeaders(originalMsg, processingErr, totalRetries),
+	}
+
+	// Use separate context so delivery does not depend on the caller context
+	publishCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := rp.publishDLQ(publishCtx, parkMsg); err != nil {
+		rp.logger.Error().
+			Err(err).
+			Str("parking_topic", rp.config.ParkingTopic).
+			Str("original_topic", originalMsg.Topic).
+			Msg("Failed to send message to parking topic")
+		return fmt.Errorf("failed to send to parking topic: %w", err)
+	}
+
+	rp.logger.Warn().
+		Str("parking_topic", rp.config.ParkingTopic).
+		Str("original_topic", originalMsg.Topic).
+		Int("partition", originalMsg.Partition).
+		Int64("offset", originalMsg.Offset).
+		Int("total_retries", totalRetries).
+		Msg("Max total retries exceeded, message parked")
+
+	return nil
+}
+
+// handle calls handler once and records the duration of the attempt.
+func (rp *RetryProcessor) handle(ctx context.Context, topic string, handler transport.Handler, envelope transport.Envelope) error {
+	start := time.Now()
+	defer func() {
+		rp.metrics.RecordProcessingTime(topic, time.Since(start))
+	}()
+	return handler.Handle(ctx, envelope)
+}
+
+// recordBreakerState exports the circuit breaker state for topic if the
+// metrics implementation supports transport.CircuitBreakerMetrics.
+func (rp *RetryProcessor) recordBreakerState(topic string) {
+	if m, ok := rp.metrics.(transport.CircuitBreakerMetrics); ok {
+		m.SetCircuitBreakerState(topic, int(rp.breaker.State()))
+	}
 }
 
 // parseMessage unmarshals a Kafka message into an Envelope.
 func (rp *RetryProcessor) parseMessage(msg kafka.Message) (*transport.Envelope, error) {
+	envelope, err := decodeEnvelope(msg)
+	if err != nil {
+		return nil, err
+	}
+	return &envelope, nil
+}
+
+// decodeEnvelope unmarshals a Kafka message into an Envelope. Kafka headers
+// are merged into Envelope.Headers without overriding headers from the body.
+func decodeEnvelope(msg kafka.Message) (transport.Envelope, error) {
 	var envelope transport.Envelope
 	if err := json.Unmarshal(msg.Value, &envelope); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+		return envelope, fmt.Errorf("%w: %w", ErrMalformedMessage, err)
 	}
-	return &envelope, nil
+	for _, header := range msg.Headers {
+		if envelope.Headers == nil {
+			envelope.Headers = make(map[string]string, len(msg.Headers))
+		}
+		if _, ok := envelope.Headers[header.Key]; !ok {
+			envelope.Headers[header.Key] = string(header.Value)
+		}
+	}
+	return envelope, nil
 }
 
 // getRetryCount extracts the retry count from message headers.
@@ -153,16 +371,18 @@ func (rp *RetryProcessor) getRetryCount(msg kafka.Message) int {
 
 // sendToDLQ publishes the message to the configured Dead Letter Queue.
 func (rp *RetryProcessor) sendToDLQ(ctx context.Context, originalMsg kafka.Message, processingErr error, totalRetries int) error {
-	if !rp.config.DLQEnabled || rp.dlqTopic == "" {
-		log.Warn().
+	dlqTopic := rp.config.DLQTopicFor(originalMsg.Topic)
+	if !rp.config.DLQEnabled || dlqTopic == "" {
+		rp.logger.Warn().
 			Str("original_topic", originalMsg.Topic).
 			Msg("DLQ disabled, dropping message")
+		rp.metrics.IncMessagesProcessed(originalMsg.Topic, "dropped")
 		return processingErr
 	}
 
 	// Build DLQ message with additional headers
 	dlqMsg := kafka.Message{
-		Topic:   rp.dlqTopic,
+		Topic:   dlqTopic,
 		Key:     originalMsg.Key,
 		Value:   originalMsg.Value,
 		Headers: rp.createDLQHeaders(originalMsg, processingErr, totalRetries),
@@ -172,21 +392,27 @@ func (rp *RetryProcessor) sendToDLQ(ctx context.Context, originalMsg kafka.Messa
 	publishCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := rp.producer.Publish(publishCtx, rp.dlqTopic, string(dlqMsg.Key), dlqMsg.Value); err != nil {
-		log.Error().
+	rp.ensureDLQTopic(publishCtx, dlqTopic)
+	if err := rp.publishDLQ(publishCtx, dlqMsg); err != nil {
+		rp.logger.Error().
 			Err(err).
-			Str("dlq_topic", rp.dlqTopic).
+			Str("dlq_topic", dlqTopic).
 			Str("original_topic", originalMsg.Topic).
 			Msg("Failed to send message to DLQ")
+		rp.metrics.IncMessagesProcessed(originalMsg.Topic, "dlq_error")
 		return fmt.Errorf("failed to send to DLQ: %w", err)
 	}
 
 	// Record DLQ metric
-	rp.metrics.IncDLQMessages(originalMsg.Topic, rp.dlqTopic)
+	if m, ok := rp.metrics.(transport.DLQCategoryMetrics); ok {
+		m.IncDLQMessagesWithCategory(originalMsg.Topic, dlqTopic, ClassifyError(processingErr))
+	} else {
+		rp.metrics.IncDLQMessages(originalMsg.Topic, dlqTopic)
+	}
 	rp.metrics.IncMessagesProcessed(originalMsg.Topic, "dlq")
 
-	log.Info().
-		Str("dlq_topic", rp.dlqTopic).
+	rp.logger.Info().
+		Str("dlq_topic", dlqTopic).
 		Str("original_topic", originalMsg.Topic).
 		Int("partition", originalMsg.Partition).
 		Int64("offset", originalMsg.Offset).
@@ -196,9 +422,40 @@ func (rp *RetryProcessor) sendToDLQ(ctx context.Context, originalMsg kafka.Messa
 	return nil
 }
 
+// ensureDLQTopic creates a per-topic DLQ once. A failure is only logged:
+// publishing may still succeed when the brokers auto-create topics.
+func (rp *RetryProcessor) ensureDLQTopic(ctx context.Context, topic string) {
+	if rp.admin == nil || rp.config.DLQNaming != DLQNamingSuffix {
+		return
+	}
+	if _, ok := rp.ensured.Load(topic); ok {
+		return
+	}
+	// -1 uses the broker defaults for partitions and replication
+	if err := rp.admin.EnsureTopic(ctx, topic, -1, -1); err != nil {
+		rp.logger.Warn().Err(err).Str("dlq_topic", topic).Msg("Failed to create DLQ topic")
+		return
+	}
+	rp.ensured.Store(topic, struct{}{})
+}
+
+// publishDLQ publishes msg, keeping its headers when the producer supports them.
+func (rp *RetryProcessor) publishDLQ(ctx context.Context, msg kafka.Message) error {
+	hp, ok := rp.producer.(transport.HeaderProducer)
+	if !ok {
+		return rp.producer.Publish(ctx, msg.Topic, string(msg.Key), msg.Value)
+	}
+
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+	return hp.PublishWithHeaders(ctx, msg.Topic, string(msg.Key), msg.Value, headers)
+}
+
 // createDLQHeaders builds headers for a DLQ message.
 func (rp *RetryProcessor) createDLQHeaders(originalMsg kafka.Message, err error, totalRetries int) []kafka.Header {
-	headers := make([]kafka.Header, 0, len(originalMsg.Headers)+4)
+	headers := make([]kafka.Header, 0, len(originalMsg.Headers)+8)
 
 	// Copy original headers
 	for _, header := range originalMsg.Headers {
@@ -219,6 +476,18 @@ func (rp *RetryProcessor) createDLQHeaders(originalMsg kafka.Message, err error,
 		Value: []byte(err.Error()),
 	})
 
+	headers = append(headers, kafka.Header{
+		Key:   ErrorCategoryHeader,
+		Value: []byte(ClassifyError(err)),
+	})
+
+	if code := transport.ErrorCode(err); code != "" {
+		headers = append(headers, kafka.Header{
+			Key:   ErrorCodeHeader,
+			Value: []byte(code),
+		})
+	}
+
 	headers = append(headers, kafka.Header{
 		Key:   rp.config.DLQTimestampHeader,
 		Value: []byte(time.Now().UTC().Format(time.RFC3339)),
@@ -226,35 +495,29 @@ func (rp *RetryProcessor) createDLQHeaders(originalMsg kafka.Message, err error,
 
 	// Include information about the original topic
 	headers = append(headers, kafka.Header{
-		Key:   "x-original-topic",
+		Key:   OriginalTopicHeader,
 		Value: []byte(originalMsg.Topic),
 	})
 
 	headers = append(headers, kafka.Header{
-		Key:   "x-original-partition",
+		Key:   OriginalPartitionHeader,
 		Value: []byte(strconv.Itoa(originalMsg.Partition)),
 	})
 
 	headers = append(headers, kafka.Header{
-		Key:   "x-original-offset",
+		Key:   OriginalOffsetHeader,
 		Value: []byte(strconv.FormatInt(originalMsg.Offset, 10)),
 	})
 
 	return headers
 }
 
-// IsRetryableError determines whether an error should be retried.
+// IsRetryableError determines whether an error should be retried. It honors
+// both RetryableError and the transport.RetryableError interface anywhere in
+// the error chain; context cancellation is never retried.
 func IsRetryableError(err error) bool {
-	if retryableErr, ok := err.(*RetryableError); ok {
-		return retryableErr.Retryable
-	}
-
-	// By default we treat errors as retryable except for specific cases.
-	// Additional logic for non-retryable errors can be added here.
-	switch err {
-	case context.Canceled, context.DeadlineExceeded:
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 		return false
-	default:
-		return true
 	}
+	return isRetryable(err)
 }
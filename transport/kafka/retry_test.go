@@ -0,0 +1,269 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"gitlab.com/zynero/shared/transport"
+)
+
+type recordingProducer struct {
+	topics []string
+}
+
+func (p *recordingProducer) Publish(_ context.Context, topic, _ string, _ []byte) error {
+	p.topics = append(p.topics, topic)
+	return nil
+}
+
+func (p *recordingProducer) Close() error { return nil }
+
+type countingHandler struct {
+	calls int
+	err   error
+}
+
+func (h *countingHandler) Handle(context.Context, transport.Envelope) error {
+	h.calls++
+	return h.err
+}
+
+func TestProcessWithRetryErrorKinds(t *testing.T) {
+	cause := errors.New("boom")
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{"transport non-retryable", transport.NewNonRetryableError(cause), 1},
+		{"kafka non-retryable", NewRetryableError(cause, false), 1},
+		{"handler error", transport.NewHandlerError("invalid", false, cause), 1},
+		{"wrapped transport non-retryable", errors.Join(cause, transport.NewNonRetryableError(cause)), 1},
+		{"kafka retryable", NewRetryableError(cause, true), 3},
+		{"transport temporary", transport.NewTemporaryError(cause, time.Millisecond), 3},
+		{"plain error", cause, 3},
+		{"transport permanent", transport.Permanent(cause), 1},
+		{"wrapped transport permanent", fmt.Errorf("charge order: %w", transport.Permanent(cause)), 1},
+		{"transport retryable", transport.Retryable(cause), 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := GetDefaultReliabilityConfig()
+			cfg.RetryCount = 2
+			cfg.RetryBackoff = time.Millisecond
+			cfg.DLQTopic = "orders-dlq"
+
+			producer := &recordingProducer{}
+			rp := NewRetryProcessor(cfg, producer)
+			handler := &countingHandler{err: tt.err}
+
+			msg := kafka.Message{
+				Topic: "orders",
+				Value: []byte(`{"event_id":"1","event_type":"order.created","payload":{}}`),
+			}
+			if err := rp.ProcessWithRetry(context.Background(), msg, handler); err != nil {
+				t.Fatalf("ProcessWithRetry() error = %v", err)
+			}
+
+			if handler.calls != tt.wantCalls {
+				t.Errorf("handler calls = %d, want %d", handler.calls, tt.wantCalls)
+			}
+			if len(producer.topics) != 1 || producer.topics[0] != "orders-dlq" {
+				t.Errorf("DLQ publishes = %v, want [orders-dlq]", producer.topics)
+			}
+		})
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	if IsRetryableError(transport.NewNonRetryableError(errors.New("bad"))) {
+		t.Error("transport non-retryable error reported as retryable")
+	}
+	if IsRetryableError(context.Canceled) {
+		t.Error("context.Canceled reported as retryable")
+	}
+	if !IsRetryableError(NewRetryableError(errors.New("busy"), true)) {
+		t.Error("retryable error reported as non-retryable")
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("handler failed: %w", context.DeadlineExceeded), ErrorCategoryTimeout},
+		{fmt.Errorf("%w: eof", ErrMalformedMessage), ErrorCategoryValidation},
+		{transport.Permanent(errors.New("invalid amount")), ErrorCategoryValidation},
+		{transport.NewHandlerError("bad_request", false, nil), ErrorCategoryValidation},
+		{fmt.Errorf("%w: %w", ErrCircuitOpen, context.DeadlineExceeded), ErrorCategoryCircuitOpen},
+		{ErrMessageTooLarge, ErrorCategoryOversized},
+		{errors.New("db unavailable"), ErrorCategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("ClassifyError(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+type recordingMetrics struct {
+	transport.NoOpMetrics
+	attempts int
+	statuses []string
+}
+
+func (m *recordingMetrics) RecordProcessingTime(string, time.Duration) { m.attempts++ }
+
+func (m *recordingMetrics) IncMessagesProcessed(_ string, status string) {
+	m.statuses = append(m.statuses, status)
+}
+
+func TestProcessWithRetryMetrics(t *testing.T) {
+	cfg := GetDefaultReliabilityConfig()
+	cfg.RetryCount = 2
+	cfg.RetryBackoff = time.Millisecond
+	cfg.DLQEnabled = false
+
+	metrics := &recordingMetrics{}
+	rp := NewRetryProcessor(cfg, &recordingProducer{})
+	rp.SetMetrics(metrics)
+
+	msg := kafka.Message{
+		Topic: "orders",
+		Value: []byte(`{"event_id":"1","event_type":"order.created","payload":{}}`),
+	}
+	if err := rp.ProcessWithRetry(context.Background(), msg, &countingHandler{err: errors.New("boom")}); err == nil {
+		t.Fatal("ProcessWithRetry() error = nil, want handler error when DLQ is disabled")
+	}
+
+	if metrics.attempts != 3 {
+		t.Errorf("recorded attempts = %d, want 3", metrics.attempts)
+	}
+	want := []string{"retry", "retry", "retry_exhausted", "dropped"}
+	if len(metrics.statuses) != len(want) {
+		t.Fatalf("statuses = %v, want %v", metrics.statuses, want)
+	}
+	for i := range want {
+		if metrics.statuses[i] != want[i] {
+			t.Fatalf("statuses = %v, want %v", metrics.statuses, want)
+		}
+	}
+}
+
+func TestProcessWithRetryMaxTotalRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		parkingTopic string
+		retryHeader  string
+		wantCalls    int
+		wantTopics   []string
+		wantErr      bool
+	}{
+		{"budget left goes to DLQ", "orders-parked", "1", 3, []string{"orders-dlq"}, false},
+		{"ceiling reached during processing", "orders-parked", "3", 2, []string{"orders-parked"}, false},
+		{"ceiling reached before processing", "orders-parked", "4", 0, []string{"orders-parked"}, false},
+		{"discarded without parking topic", "", "4", 0, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := GetDefaultReliabilityConfig()
+			cfg.RetryCount = 2
+			cfg.RetryBackoff = time.Millisecond
+			cfg.DLQTopic = "orders-dlq"
+			cfg.MaxTotalRetries = 4
+			cfg.ParkingTopic = tt.parkingTopic
+
+			metrics := &recordingMetrics{}
+			producer := &recordingProducer{}
+			rp := NewRetryProcessor(cfg, producer)
+			rp.SetMetrics(metrics)
+			handler := &countingHandler{err: errors.New("boom")}
+
+			msg := kafka.Message{
+				Topic:   "orders",
+				Value:   []byte(`{"event_id":"1","event_type":"order.created","payload":{}}`),
+				Headers: []kafka.Header{{Key: cfg.DLQRetryHeader, Value: []byte(tt.retryHeader)}},
+			}
+			err := rp.ProcessWithRetry(context.Background(), msg, handler)
+			if tt.wantErr {
+				if !errors.Is(err, ErrMaxRetriesExceeded) {
+					t.Fatalf("ProcessWithRetry() error = %v, want ErrMaxRetriesExceeded", err)
+				}
+			} else if err != nil {
+				t.Fatalf("ProcessWithRetry() error = %v", err)
+			}
+
+			if handler.calls != tt.wantCalls {
+				t.Errorf("handler calls = %d, want %d", handler.calls, tt.wantCalls)
+			}
+			if len(producer.topics) != len(tt.wantTopics) || (len(tt.wantTopics) > 0 && producer.topics[0] != tt.wantTopics[0]) {
+				t.Errorf("publishes = %v, want %v", producer.topics, tt.wantTopics)
+			}
+			parked := metrics.statuses[len(metrics.statuses)-1] == "permanently_failed"
+			if parked != (tt.wantTopics == nil || tt.wantTopics[0] == "orders-parked") {
+				t.Errorf("statuses = %v", metrics.statuses)
+			}
+		})
+	}
+}
+
+type recordingAdmin struct {
+	created []string
+}
+
+func (a *recordingAdmin) EnsureTopic(_ context.Context, name string, _, _ int) error {
+	a.created = append(a.created, name)
+	return nil
+}
+
+func TestProcessWithRetryDLQNaming(t *testing.T) {
+	cfg := GetDefaultReliabilityConfig()
+	cfg.RetryCount = 0
+	cfg.DLQNaming = DLQNamingSuffix
+	cfg.DLQSuffix = "-failed"
+
+	producer := &recordingProducer{}
+	admin := &recordingAdmin{}
+	rp := NewRetryProcessor(cfg, producer)
+	rp.admin = admin
+	handler := &countingHandler{err: errors.New("boom")}
+
+	for _, topic := range []string{"orders", "payments", "orders"} {
+		msg := kafka.Message{
+			Topic: topic,
+			Value: []byte(`{"event_id":"1","event_type":"order.created","payload":{}}`),
+		}
+		if err := rp.ProcessWithRetry(context.Background(), msg, handler); err != nil {
+			t.Fatalf("ProcessWithRetry() error = %v", err)
+		}
+	}
+
+	wantPublished := []string{"orders-failed", "payments-failed", "orders-failed"}
+	if !reflect.DeepEqual(producer.topics, wantPublished) {
+		t.Errorf("DLQ publishes = %v, want %v", producer.topics, wantPublished)
+	}
+	wantCreated := []string{"orders-failed", "payments-failed"}
+	if !reflect.DeepEqual(admin.created, wantCreated) {
+		t.Errorf("created topics = %v, want %v", admin.created, wantCreated)
+	}
+
+	cfg.DLQNaming = DLQNamingSingle
+	cfg.DLQTopic = "all-dlq"
+	if got := cfg.DLQTopicFor("orders"); got != "all-dlq" {
+		t.Errorf("DLQTopicFor() with single naming = %q, want all-dlq", got)
+	}
+	cfg.DLQNaming = DLQNamingSuffix
+	cfg.DLQSuffix = ""
+	if got := cfg.DLQTopicFor("orders"); got != "orders.dlq" {
+		t.Errorf("DLQTopicFor() with default suffix = %q, want orders.dlq", got)
+	}
+}
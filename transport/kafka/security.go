@@ -0,0 +1,128 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// TLSConfig describes TLS settings shared by producer and consumer.
+type TLSConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// CAFile verifies brokers, system roots are used if empty
+	CAFile string `mapstructure:"ca_file"`
+	// CertFile and KeyFile enable client certificate authentication
+	CertFile           string `mapstructure:"cert_file"`
+	KeyFile            string `mapstructure:"key_file"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+}
+
+// newDialer returns a dialer applying SASL and TLS from cfg.
+func newDialer(cfg Config) (*kafka.Dialer, error) {
+	mechanism, tlsCfg, err := security(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &kafka.Dialer{
+		Timeout:       10 * time.Second,
+		DualStack:     true,
+		SASLMechanism: mechanism,
+		TLS:           tlsCfg,
+	}, nil
+}
+
+// newTransport returns a transport for kafka.Writer and kafka.Client applying
+// SASL and TLS from cfg.
+func newTransport(cfg Config) (*kafka.Transport, error) {
+	mechanism, tlsCfg, err := security(cfg)
+	if err != nil {
+		return nil, err
+	}
+	kafkaTransport := &kafka.Transport{TLS: tlsCfg}
+	if mechanism != nil {
+		kafkaTransport.SASL = mechanism
+	}
+	return kafkaTransport, nil
+}
+
+// security builds the SASL mechanism and TLS configuration from cfg. Either
+// is nil when disabled.
+func security(cfg Config) (sasl.Mechanism, *tls.Config, error) {
+	mechanism, err := saslMechanism(cfg.SASL)
+	if err != nil {
+		return nil, nil, err
+	}
+	tlsCfg, err := tlsConfig(cfg.TLS)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mechanism, tlsCfg, nil
+}
+
+// tlsConfig builds a *tls.Config or returns nil if TLS is disabled.
+func tlsConfig(cfg *TLSConfig) (*tls.Config, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		ca, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
+// saslMechanism создает механизм SASL согласно cfg.Mechanism или возвращает
+// nil, если SASL выключен. Пустой Mechanism означает SCRAM-SHA-512.
+func saslMechanism(cfg *SASLConfig) (sasl.Mechanism, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	var (
+		mechanism sasl.Mechanism
+		err       error
+	)
+	switch cfg.Mechanism {
+	case "PLAIN":
+		mechanism = plain.Mechanism{Username: cfg.Username, Password: cfg.Password}
+	case "SCRAM-SHA-256":
+		mechanism, err = scram.Mechanism(scram.SHA256, cfg.Username, cfg.Password)
+	case "SCRAM-SHA-512", "":
+		mechanism, err = scram.Mechanism(scram.SHA512, cfg.Username, cfg.Password)
+	default:
+		return nil, fmt.Errorf("unsupported SASL mechanism %q", cfg.Mechanism)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SASL mechanism: %w", err)
+	}
+	return mechanism, nil
+}
@@ -0,0 +1,92 @@
+package kafka
+
+import "testing"
+
+func TestSASLMechanism(t *testing.T) {
+	tests := []struct {
+		mechanism string
+		want      string
+	}{
+		{"PLAIN", "PLAIN"},
+		{"SCRAM-SHA-256", "SCRAM-SHA-256"},
+		{"SCRAM-SHA-512", "SCRAM-SHA-512"},
+		{"", "SCRAM-SHA-512"},
+	}
+
+	for _, tt := range tests {
+		cfg := &SASLConfig{Enabled: true, Mechanism: tt.mechanism, Username: "user", Password: "secret"}
+		m, err := saslMechanism(cfg)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.mechanism, err)
+		}
+		if m.Name() != tt.want {
+			t.Errorf("%q: got mechanism %s, want %s", tt.mechanism, m.Name(), tt.want)
+		}
+	}
+}
+
+func TestSASLMechanismDisabled(t *testing.T) {
+	if m, err := saslMechanism(nil); m != nil || err != nil {
+		t.Errorf("nil config: got %v, %v", m, err)
+	}
+	if m, err := saslMechanism(&SASLConfig{Mechanism: "PLAIN"}); m != nil || err != nil {
+		t.Errorf("disabled config: got %v, %v", m, err)
+	}
+}
+
+func TestSASLMechanismUnsupported(t *testing.T) {
+	if _, err := saslMechanism(&SASLConfig{Enabled: true, Mechanism: "GSSAPI"}); err == nil {
+		t.Error("expected error for unsupported mechanism")
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	if cfg, err := tlsConfig(&TLSConfig{Enabled: false, CAFile: "missing.pem"}); cfg != nil || err != nil {
+		t.Errorf("disabled TLS: got %v, %v", cfg, err)
+	}
+	if _, err := tlsConfig(&TLSConfig{Enabled: true, CAFile: "missing.pem"}); err == nil {
+		t.Error("expected error for missing CA file")
+	}
+	cfg, err := tlsConfig(&TLSConfig{Enabled: true})
+	if err != nil || cfg == nil {
+		t.Fatalf("enabled TLS without files: got %v, %v", cfg, err)
+	}
+}
+
+func TestTLSWithoutSASL(t *testing.T) {
+	cfg := Config{
+		Brokers: []string{"localhost:9093"},
+		TLS:     &TLSConfig{Enabled: true, InsecureSkipVerify: true},
+	}
+
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		t.Fatalf("newDialer() error = %v", err)
+	}
+	if dialer.TLS == nil || dialer.SASLMechanism != nil {
+		t.Errorf("dialer TLS = %v, SASL = %v, want TLS only", dialer.TLS, dialer.SASLMechanism)
+	}
+
+	kafkaTransport, err := newTransport(cfg)
+	if err != nil {
+		t.Fatalf("newTransport() error = %v", err)
+	}
+	if kafkaTransport.TLS == nil || kafkaTransport.SASL != nil {
+		t.Errorf("transport TLS = %v, SASL = %v, want TLS only", kafkaTransport.TLS, kafkaTransport.SASL)
+	}
+}
+
+func TestNewConsumerRejectsInvalidSecurity(t *testing.T) {
+	cfg := Config{
+		Brokers: []string{"localhost:9093"},
+		TLS:     &TLSConfig{Enabled: true, CAFile: "missing.pem"},
+	}
+	cfg.Consumer.GroupID = "orders"
+
+	if c, err := NewConsumer(cfg, "orders", nil); err == nil || c != nil {
+		t.Errorf("NewConsumer() = %v, %v, want an error instead of an insecure connection", c, err)
+	}
+	if c, err := NewMultiConsumer(cfg, []string{"orders", "payments"}, nil); err == nil || c != nil {
+		t.Errorf("NewMultiConsumer() = %v, %v, want an error", c, err)
+	}
+}
@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// DefaultTraceHeader имя заголовка W3C trace context
+const DefaultTraceHeader = "traceparent"
+
+// TracingConfig настройки передачи W3C trace context через заголовки Kafka
+type TracingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Header заголовок со значением traceparent, по умолчанию DefaultTraceHeader
+	Header string `mapstructure:"header"`
+}
+
+// header возвращает настроенное имя заголовка traceparent
+func (tc TracingConfig) header() string {
+	if tc.Header == "" {
+		return DefaultTraceHeader
+	}
+	return tc.Header
+}
+
+// traceCarrier адаптирует карту заголовков к propagation.TextMapCarrier и
+// хранит traceparent под настроенным именем заголовка
+type traceCarrier struct {
+	headers map[string]string
+	header  string
+}
+
+func (c traceCarrier) key(k string) string {
+	if k == DefaultTraceHeader {
+		return c.header
+	}
+	return k
+}
+
+func (c traceCarrier) Get(key string) string { return c.headers[c.key(key)] }
+
+func (c traceCarrier) Set(key, value string) { c.headers[c.key(key)] = value }
+
+func (c traceCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.headers))
+	for k := range c.headers {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+var tracePropagator = propagation.TraceContext{}
+
+// injectTrace добавляет trace context из ctx в заголовки. Если трассировка
+// отключена или в ctx нет активного span, headers возвращаются без изменений.
+func injectTrace(ctx context.Context, cfg TracingConfig, headers map[string]string) map[string]string {
+	if !cfg.Enabled {
+		return headers
+	}
+
+	carrier := traceCarrier{headers: make(map[string]string, len(headers)+2), header: cfg.header()}
+	for k, v := range headers {
+		carrier.headers[k] = v
+	}
+	tracePropagator.Inject(ctx, carrier)
+	if len(carrier.headers) == 0 {
+		return headers
+	}
+	return carrier.headers
+}
+
+// extractTrace возвращает ctx с trace context из заголовков msg
+func extractTrace(ctx context.Context, cfg TracingConfig, msg kafka.Message) context.Context {
+	if !cfg.Enabled || len(msg.Headers) == 0 {
+		return ctx
+	}
+
+	carrier := traceCarrier{headers: make(map[string]string, len(msg.Headers)), header: cfg.header()}
+	for _, h := range msg.Headers {
+		carrier.headers[h.Key] = string(h.Value)
+	}
+	return tracePropagator.Extract(ctx, carrier)
+}
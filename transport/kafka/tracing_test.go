@@ -0,0 +1,50 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracePropagation(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanCtx)
+	cfg := TracingConfig{Enabled: true, Header: "x-trace"}
+
+	original := map[string]string{"tenant": "acme"}
+	headers := injectTrace(ctx, cfg, original)
+	if want := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"; headers["x-trace"] != want {
+		t.Errorf("x-trace = %q, want %q", headers["x-trace"], want)
+	}
+	if _, ok := headers[DefaultTraceHeader]; ok {
+		t.Error("traceparent written despite custom header")
+	}
+	if headers["tenant"] != "acme" || len(original) != 1 {
+		t.Errorf("headers = %v, original = %v: existing headers must be kept and not modified", headers, original)
+	}
+
+	msg := kafka.Message{Headers: []kafka.Header{{Key: "x-trace", Value: []byte(headers["x-trace"])}}}
+	extracted := trace.SpanContextFromContext(extractTrace(context.Background(), cfg, msg))
+	if extracted.TraceID() != traceID || extracted.SpanID() != spanID || !extracted.IsRemote() {
+		t.Errorf("extracted span context = %+v, want remote %s/%s", extracted, traceID, spanID)
+	}
+
+	disabled := TracingConfig{Header: "x-trace"}
+	if got := injectTrace(ctx, disabled, original); len(got) != 1 {
+		t.Errorf("injectTrace() with tracing disabled = %v", got)
+	}
+	if extractTrace(context.Background(), disabled, msg) != context.Background() {
+		t.Error("extractTrace() with tracing disabled changed the context")
+	}
+	if got := injectTrace(context.Background(), cfg, nil); got != nil {
+		t.Errorf("injectTrace() without a span = %v, want nil", got)
+	}
+}
@@ -0,0 +1,343 @@
+// Package memory реализует транспорт в памяти процесса для тестов: Producer
+// сохраняет сообщения в Broker, Consumer передает их обработчику. Сообщения
+// сериализуются в transport.Envelope так же, как в Kafka.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"gitlab.com/zynero/shared/transport"
+)
+
+// Message представляет опубликованное сообщение
+type Message struct {
+	Topic   string
+	Key     string
+	Value   []byte
+	Headers map[string]string
+}
+
+// Broker хранит опубликованные сообщения по топикам
+type Broker struct {
+	mu       sync.RWMutex
+	messages map[string][]Message
+	notify   chan struct{} // закрывается и заменяется при каждой публикации
+}
+
+// NewBroker создает пустой брокер
+func NewBroker() *Broker {
+	return &Broker{
+		messages: make(map[string][]Message),
+		notify:   make(chan struct{}),
+	}
+}
+
+func (b *Broker) publish(msg Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages[msg.Topic] = append(b.messages[msg.Topic], msg)
+	close(b.notify)
+	b.notify = make(chan struct{})
+}
+
+// from возвращает сообщения топика начиная с offset и канал уведомления о новых
+func (b *Broker) from(topic string, offset int) ([]Message, <-chan struct{}) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	msgs := b.messages[topic]
+	if offset >= len(msgs) {
+		return nil, b.notify
+	}
+	return append([]Message(nil), msgs[offset:]...), b.notify
+}
+
+// Messages возвращает копию сообщений, опубликованных в топик
+func (b *Broker) Messages(topic string) []Message {
+	msgs, _ := b.from(topic, 0)
+	return msgs
+}
+
+// Envelopes декодирует сообщения топика в transport.Envelope
+func (b *Broker) Envelopes(topic string) ([]transport.Envelope, error) {
+	msgs := b.Messages(topic)
+	envelopes := make([]transport.Envelope, 0, len(msgs))
+	for _, msg := range msgs {
+		envelope, err := decodeEnvelope(msg)
+		if err != nil {
+			return nil, err
+		}
+		envelopes = append(envelopes, envelope)
+	}
+	return envelopes, nil
+}
+
+// Reset удаляет все сообщения. Используйте его до создания consumer: созданные
+// ранее consumer сохраняют свою позицию.
+func (b *Broker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages = make(map[string][]Message)
+}
+
+// Producer реализует transport.HeaderProducer поверх Broker
+type Producer struct {
+	broker       *Broker
+	defaultTopic string
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+var _ transport.HeaderProducer = (*Producer)(nil)
+
+// NewProducer создает producer. defaultTopic используется, если в Publish
+// передан пустой топик.
+func NewProducer(broker *Broker, defaultTopic string) *Producer {
+	return &Producer{broker: broker, defaultTopic: defaultTopic}
+}
+
+// Publish сохраняет сообщение в брокере
+func (p *Producer) Publish(ctx context.Context, topic, key string, value []byte) error {
+	return p.PublishWithHeaders(ctx, topic, key, value, nil)
+}
+
+// PublishWithHeaders сохраняет сообщение с заголовками в брокере
+func (p *Producer) PublishWithHeaders(ctx context.Context, topic, key string, value []byte, headers map[string]string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return fmt.Errorf("producer is closed")
+	}
+
+	if topic == "" {
+		topic = p.defaultTopic
+	}
+
+	var copied map[string]string
+	if len(headers) > 0 {
+		copied = make(map[string]string, len(headers))
+		for k, v := range headers {
+			copied[k] = v
+		}
+	}
+
+	p.broker.publish(Message{
+		Topic:   topic,
+		Key:     key,
+		Value:   append([]byte(nil), value...),
+		Headers: copied,
+	})
+	return nil
+}
+
+// Close закрывает producer
+func (p *Producer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+// Consumer реализует transport.Consumer поверх Broker. Сообщения каждого
+// топика передаются обработчику по порядку, начиная с первого опубликованного.
+type Consumer struct {
+	broker  *Broker
+	topics  []string
+	handler transport.Handler
+
+	mu        sync.Mutex
+	offsets   map[string]int
+	errs      []error
+	isRunning bool
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+}
+
+var _ transport.Consumer = (*Consumer)(nil)
+
+// NewConsumer создает consumer топика
+func NewConsumer(broker *Broker, topic string, handler transport.Handler) *Consumer {
+	return NewMultiConsumer(broker, []string{topic}, handler)
+}
+
+// NewMultiConsumer создает consumer нескольких топиков. Как и в Kafka, топик
+// сообщения доступен обработчику через transport.TopicFromContext.
+func NewMultiConsumer(broker *Broker, topics []string, handler transport.Handler) *Consumer {
+	return &Consumer{
+		broker:  broker,
+		topics:  topics,
+		handler: handler,
+		offsets: make(map[string]int, len(topics)),
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+}
+
+// pending возвращает необработанные сообщения всех топиков и канал
+// уведомления о новых публикациях
+func (c *Consumer) pending() ([]Message, <-chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var (
+		pending []Message
+		notify  <-chan struct{}
+	)
+	for _, topic := range c.topics {
+		msgs, topicNotify := c.broker.from(topic, c.offsets[topic])
+		// Канал первого чтения закроется при любой публикации после него,
+		// поэтому сообщения, опубликованные во время обхода, не теряются
+		if notify == nil {
+			notify = topicNotify
+		}
+		pending = append(pending, msgs...)
+	}
+	return pending, notify
+}
+
+// Poll синхронно обрабатывает все ожидающие сообщения и возвращает их число.
+// Ошибки обработчика сохраняются и доступны через Errors. Poll не следует
+// вызывать одновременно с Run.
+func (c *Consumer) Poll(ctx context.Context) (int, error) {
+	msgs, _ := c.pending()
+	for i, msg := range msgs {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+		c.handle(ctx, msg)
+	}
+	return len(msgs), nil
+}
+
+// Errors возвращает ошибки обработчика и декодирования
+func (c *Consumer) Errors() []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]error(nil), c.errs...)
+}
+
+func (c *Consumer) handle(ctx context.Context, msg Message) {
+	err := func() error {
+		envelope, err := decodeEnvelope(msg)
+		if err != nil {
+			return err
+		}
+		return c.handler.Handle(transport.ContextWithTopic(ctx, msg.Topic), envelope)
+	}()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.offsets[msg.Topic]++
+	if err != nil {
+		c.errs = append(c.errs, err)
+	}
+}
+
+// Run обрабатывает сообщения по мере публикации до отмены ctx или Stop
+func (c *Consumer) Run(ctx context.Context) error {
+	c.mu.Lock()
+	if c.isRunning {
+		c.mu.Unlock()
+		return fmt.Errorf("consumer is already running")
+	}
+	c.isRunning = true
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		c.isRunning = false
+		c.mu.Unlock()
+		close(c.doneCh)
+	}()
+
+	for {
+		msgs, notify := c.pending()
+		for _, msg := range msgs {
+			c.handle(ctx, msg)
+		}
+		if len(msgs) > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.stopCh:
+			return nil
+		case <-notify:
+		}
+	}
+}
+
+// Stop инициирует остановку Run
+func (c *Consumer) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.isRunning {
+		return
+	}
+	select {
+	case <-c.stopCh:
+	default:
+		close(c.stopCh)
+	}
+}
+
+// Wait ожидает завершения Run с таймаутом
+func (c *Consumer) Wait(timeout time.Duration) error {
+	select {
+	case <-c.doneCh:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("consumer shutdown timeout after %v", timeout)
+	}
+}
+
+// Close останавливает consumer
+func (c *Consumer) Close() error {
+	c.Stop()
+	return nil
+}
+
+// decodeEnvelope декодирует сообщение так же, как Kafka consumer: заголовки
+// сообщения дополняют Envelope.Headers
+func decodeEnvelope(msg Message) (transport.Envelope, error) {
+	var envelope transport.Envelope
+	if err := json.Unmarshal(msg.Value, &envelope); err != nil {
+		return envelope, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	for k, v := range msg.Headers {
+		if envelope.Headers == nil {
+			envelope.Headers = make(map[string]string, len(msg.Headers))
+		}
+		if _, ok := envelope.Headers[k]; !ok {
+			envelope.Headers[k] = v
+		}
+	}
+	return envelope, nil
+}
+
+// NewEnvelope создает сериализованный Envelope для публикации в тестах
+func NewEnvelope(eventType string, payload any) ([]byte, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return json.Marshal(transport.Envelope{
+		EventID:       uuid.NewString(),
+		EventType:     eventType,
+		OccurredAt:    time.Now().UTC(),
+		Payload:       payloadBytes,
+		SchemaVersion: transport.DefaultSchemaVersion,
+		ContentType:   "application/json",
+	})
+}
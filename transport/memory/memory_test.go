@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gitlab.com/zynero/shared/transport"
+	"gitlab.com/zynero/shared/transport/kafka"
+)
+
+type recordingHandler struct {
+	envelopes []transport.Envelope
+	topics    []string
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, envelope transport.Envelope) error {
+	h.envelopes = append(h.envelopes, envelope)
+	h.topics = append(h.topics, transport.TopicFromContext(ctx))
+	return nil
+}
+
+func TestPublishAndConsume(t *testing.T) {
+	broker := NewBroker()
+	publisher := kafka.NewKafkaEventPublisher(NewProducer(broker, ""), "orders")
+
+	ctx := context.Background()
+	if err := publisher.PublishWithHeaders(ctx, "order.created", "order-1", map[string]string{"id": "1"}, map[string]string{"source": "test"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	envelopes, err := broker.Envelopes("orders")
+	if err != nil {
+		t.Fatalf("envelopes: %v", err)
+	}
+	if len(envelopes) != 1 || envelopes[0].EventID != "order-1" {
+		t.Fatalf("unexpected published envelopes: %+v", envelopes)
+	}
+
+	handler := &recordingHandler{}
+	consumer := NewConsumer(broker, "orders", handler)
+	n, err := consumer.Poll(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("poll: n=%d err=%v", n, err)
+	}
+	if got := handler.envelopes[0]; got.EventType != "order.created" || got.Headers["source"] != "test" {
+		t.Errorf("unexpected consumed envelope: %+v", got)
+	}
+	if handler.topics[0] != "orders" {
+		t.Errorf("topic not propagated: %q", handler.topics[0])
+	}
+
+	// Повторный Poll не передает уже обработанные сообщения
+	if n, _ := consumer.Poll(ctx); n != 0 {
+		t.Errorf("expected no pending messages, got %d", n)
+	}
+}
+
+type replyingHandler struct {
+	producer transport.HeaderProducer
+}
+
+func (h *replyingHandler) Handle(ctx context.Context, envelope transport.Envelope) error {
+	return transport.Reply(ctx, h.producer, envelope, "order.accepted", map[string]string{"status": "ok"})
+}
+
+func TestRequestReply(t *testing.T) {
+	broker := NewBroker()
+	producer := NewProducer(broker, "")
+	rr := transport.NewRequestReply(producer, "orders.replies", time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	requests := NewConsumer(broker, "orders.requests", &replyingHandler{producer: producer})
+	replies := NewConsumer(broker, "orders.replies", rr)
+	go func() { _ = requests.Run(ctx) }()
+	go func() { _ = replies.Run(ctx) }()
+
+	reply, err := rr.Request(ctx, "orders.requests", "order.create", map[string]string{"id": "1"})
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if reply.EventType != "order.accepted" || reply.Headers[transport.CorrelationIDHeader] == "" {
+		t.Errorf("unexpected reply: %+v", reply)
+	}
+}
+
+func TestRequestReplyTimeout(t *testing.T) {
+	broker := NewBroker()
+	rr := transport.NewRequestReply(NewProducer(broker, ""), "orders.replies", 10*time.Millisecond)
+
+	_, err := rr.Request(context.Background(), "orders.requests", "order.create", nil)
+	if !errors.Is(err, transport.ErrReplyTimeout) {
+		t.Fatalf("expected ErrReplyTimeout, got %v", err)
+	}
+}
+
+func TestMultiConsumer(t *testing.T) {
+	broker := NewBroker()
+	producer := NewProducer(broker, "")
+	ctx := context.Background()
+
+	for _, topic := range []string{"orders", "payments", "orders", "audit"} {
+		value, err := NewEnvelope(topic+".event", map[string]string{"topic": topic})
+		if err != nil {
+			t.Fatalf("envelope: %v", err)
+		}
+		if err := producer.Publish(ctx, topic, "", value); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	handler := &recordingHandler{}
+	consumer := NewMultiConsumer(broker, []string{"orders", "payments"}, handler)
+	n, err := consumer.Poll(ctx)
+	if err != nil || n != 3 {
+		t.Fatalf("poll: n=%d err=%v", n, err)
+	}
+	want := []string{"orders", "orders", "payments"}
+	for i, topic := range want {
+		if handler.topics[i] != topic {
+			t.Fatalf("consumed topics = %v, want %v", handler.topics, want)
+		}
+	}
+
+	if n, _ := consumer.Poll(ctx); n != 0 {
+		t.Errorf("expected no pending messages, got %d", n)
+	}
+}
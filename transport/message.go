@@ -5,9 +5,33 @@ import (
 	"time"
 )
 
+// DefaultSchemaVersion версия схемы для конвертов без явно указанной версии
+const DefaultSchemaVersion = "v1"
+
 type Envelope struct {
 	EventID    string          `json:"event_id"`
 	EventType  string          `json:"event_type"`
 	OccurredAt time.Time       `json:"occurred_at"`
 	Payload    json.RawMessage `json:"payload"`
+	// SchemaVersion версия схемы Payload, DefaultSchemaVersion если не указана
+	SchemaVersion string `json:"schema_version,omitempty"`
+	// ContentType формат Payload, например application/json
+	ContentType string `json:"content_type,omitempty"`
+	// Headers содержит метаданные события (трассировка, content-type и т.п.)
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// UnmarshalJSON декодирует конверт, подставляя DefaultSchemaVersion для
+// сообщений, опубликованных до появления версий схемы.
+func (e *Envelope) UnmarshalJSON(data []byte) error {
+	type envelope Envelope
+	var decoded envelope
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.SchemaVersion == "" {
+		decoded.SchemaVersion = DefaultSchemaVersion
+	}
+	*e = Envelope(decoded)
+	return nil
 }
@@ -8,9 +8,18 @@ import (
 type Metrics interface {
 	// Consumer метрики
 	IncMessagesReceived(topic string, partition int)
-	IncMessagesProcessed(topic string, status string) // status: success, error, retry, dlq
+	// status: success, error - итог обработки сообщения consumer;
+	// retry, retry_success, retry_exhausted, non_retryable, parse_error,
+	// circuit_open - ход повторов; dlq - сообщение отправлено в DLQ,
+	// dlq_error - отправка в DLQ не удалась, dropped - DLQ отключена и
+	// сообщение отброшено, permanently_failed - превышен MaxTotalRetries,
+	// oversized - пропущено сообщение больше MaxBytes, rate_limited -
+	// сообщение ключа сверх RateLimit отправлено в топик парковки
+	IncMessagesProcessed(topic string, status string)
+	// RecordProcessingTime вызывается на каждую попытку обработки, включая повторы
 	RecordProcessingTime(topic string, duration time.Duration)
 	IncRetryAttempts(topic string, attempt int)
+	SetConsumerLag(topic string, partition int, lag int64)
 
 	// Producer метрики
 	IncMessagesSent(topic string, status string) // status: success, error
@@ -25,6 +34,19 @@ type Metrics interface {
 	RecordUptime(duration time.Duration)
 }
 
+// DLQCategoryMetrics дополняет IncDLQMessages причиной отправки сообщения в DLQ
+type DLQCategoryMetrics interface {
+	// category - причина из фиксированного набора:
+	// timeout, validation, circuit_open, oversized, unknown
+	IncDLQMessagesWithCategory(originalTopic, dlqTopic, category string)
+}
+
+// CircuitBreakerMetrics экспортирует состояние circuit breaker обработчика топика
+type CircuitBreakerMetrics interface {
+	// SetCircuitBreakerState: 0 - closed, 1 - half-open, 2 - open
+	SetCircuitBreakerState(topic string, state int)
+}
+
 // NoOpMetrics реализация метрик, которая ничего не делает (для тестов/отключения)
 type NoOpMetrics struct{}
 
@@ -32,6 +54,7 @@ func (m *NoOpMetrics) IncMessagesReceived(topic string, partition int)
 func (m *NoOpMetrics) IncMessagesProcessed(topic string, status string)          {}
 func (m *NoOpMetrics) RecordProcessingTime(topic string, duration time.Duration) {}
 func (m *NoOpMetrics) IncRetryAttempts(topic string, attempt int)                {}
+func (m *NoOpMetrics) SetConsumerLag(topic string, partition int, lag int64)     {}
 func (m *NoOpMetrics) IncMessagesSent(topic string, status string)               {}
 func (m *NoOpMetrics) RecordPublishTime(topic string, duration time.Duration)    {}
 func (m *NoOpMetrics) IncDLQMessages(originalTopic, dlqTopic string)             {}
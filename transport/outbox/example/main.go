@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"gitlab.com/zynero/shared/database"
+	"gitlab.com/zynero/shared/transport/memory"
+	"gitlab.com/zynero/shared/transport/outbox"
+)
+
+// OrderCreated представляет событие создания заказа
+type OrderCreated struct {
+	OrderID int64  `json:"order_id"`
+	Status  string `json:"status"`
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// 1. Подключение к PostgreSQL
+	db, err := database.New(database.Config{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "postgres",
+		Password: "postgres",
+		DBName:   "orders",
+		SSLMode:  "disable",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	// 2. Outbox публикует события через любой transport.Producer,
+	// здесь - через in-memory брокер вместо Kafka
+	broker := memory.NewBroker()
+	producer := memory.NewProducer(broker, "orders")
+	defer producer.Close()
+
+	ob := outbox.New(db.Pool(), producer, outbox.Config{Topic: "orders"})
+
+	// 3. Создание таблицы outbox (обычно выполняется миграцией)
+	if err := db.Exec(ctx, ob.CreateTableSQL()); err != nil {
+		log.Fatal(err)
+	}
+
+	// 4. Событие сохраняется в той же транзакции, что и бизнес-данные
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// ... изменения бизнес-данных в tx
+	if err := ob.Enqueue(ctx, tx, "order.created", OrderCreated{OrderID: 1, Status: "new"}); err != nil {
+		log.Fatal(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	// 5. Публикация одной пачки; в сервисе relay запускается через go ob.Run(ctx)
+	sent, err := ob.RelayOnce(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("published %d events", sent)
+
+	envelopes, err := broker.Envelopes("orders")
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, envelope := range envelopes {
+		log.Printf("event %s %s: %s", envelope.EventID, envelope.EventType, envelope.Payload)
+	}
+}
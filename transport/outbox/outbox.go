@@ -0,0 +1,224 @@
+// Package outbox реализует паттерн transactional outbox: события сохраняются
+// в таблицу в той же транзакции, что и бизнес-данные, а фоновый relay
+// публикует их через transport.Producer. Доставка at-least-once: событие
+// может быть опубликовано повторно, если relay упал до отметки об отправке,
+// поэтому потребители должны дедуплицировать по EventID (ключ сообщения).
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	json "github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	platformlogger "gitlab.com/zynero/shared/logger"
+	"gitlab.com/zynero/shared/transport"
+)
+
+const (
+	defaultTable        = "outbox"
+	defaultBatchSize    = 100
+	defaultPollInterval = time.Second
+)
+
+// LoggerComponent значение поля component в записях журнала outbox
+const LoggerComponent = "outbox"
+
+// defaultLogger возвращает глобальный логгер с полем component=outbox, чтобы
+// записи содержали глобальные поля приложения
+func defaultLogger() *platformlogger.Logger {
+	return platformlogger.GetGlobal().With().Str("component", LoggerComponent).Logger()
+}
+
+// Config представляет настройки outbox
+type Config struct {
+	// Table имя таблицы outbox, по умолчанию "outbox"
+	Table string `mapstructure:"table"`
+	// Topic топик публикации, пустой - топик producer по умолчанию
+	Topic string `mapstructure:"topic"`
+	// BatchSize максимальное число событий за один проход relay
+	BatchSize int `mapstructure:"batch_size"`
+	// PollInterval интервал опроса таблицы relay
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+}
+
+// Pool открывает транзакции relay, его реализует *pgxpool.Pool
+type Pool interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Outbox сохраняет события в таблицу и публикует их в транспорт
+type Outbox struct {
+	pool     Pool
+	producer transport.Producer
+	config   Config
+	table    string
+	logger   *platformlogger.Logger
+}
+
+// New создает outbox. pool используется relay для чтения неотправленных
+// событий, producer - для их публикации.
+func New(pool Pool, producer transport.Producer, cfg Config) *Outbox {
+	if cfg.Table == "" {
+		cfg.Table = defaultTable
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+
+	return &Outbox{
+		pool:     pool,
+		producer: producer,
+		config:   cfg,
+		table:    pgx.Identifier{cfg.Table}.Sanitize(),
+		logger:   defaultLogger(),
+	}
+}
+
+// SetLogger устанавливает логгер relay. По умолчанию используется глобальный
+// логгер с полем component=outbox. Вызывается до Run.
+func (o *Outbox) SetLogger(l *platformlogger.Logger) {
+	o.logger = l
+}
+
+// CreateTableSQL возвращает DDL таблицы outbox для миграций
+func (o *Outbox) CreateTableSQL() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+	id         BIGSERIAL PRIMARY KEY,
+	event_id   TEXT        NOT NULL UNIQUE,
+	event_type TEXT        NOT NULL,
+	envelope   BYTEA       NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	sent_at    TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS %[2]s ON %[1]s (id) WHERE sent_at IS NULL;`,
+		o.table, pgx.Identifier{o.config.Table + "_unsent_idx"}.Sanitize())
+}
+
+// Enqueue сохраняет событие в outbox в рамках транзакции tx вызывающего кода.
+// Событие будет опубликовано relay только после фиксации транзакции.
+func (o *Outbox) Enqueue(ctx context.Context, tx pgx.Tx, eventType string, payload any) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	envelope := transport.Envelope{
+		EventID:       uuid.NewString(),
+		EventType:     eventType,
+		OccurredAt:    time.Now().UTC(),
+		Payload:       payloadBytes,
+		SchemaVersion: transport.DefaultSchemaVersion,
+		ContentType:   "application/json",
+	}
+
+	envelopeBytes, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (event_id, event_type, envelope) VALUES ($1, $2, $3)", o.table)
+	if _, err := tx.Exec(ctx, query, envelope.EventID, eventType, envelopeBytes); err != nil {
+		return fmt.Errorf("failed to enqueue event: %w", err)
+	}
+	return nil
+}
+
+// Run запускает relay и блокирует выполнение до отмены ctx
+func (o *Outbox) Run(ctx context.Context) error {
+	ticker := time.NewTicker(o.config.PollInterval)
+	defer ticker.Stop()
+
+	o.logger.Info().Str("table", o.config.Table).Msg("Starting outbox relay")
+
+	for {
+		// Публикуем пачками, пока есть неотправленные события
+		for {
+			sent, err := o.RelayOnce(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				o.logger.Error().Err(err).Msg("Outbox relay failed")
+				break
+			}
+			if sent < o.config.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			o.logger.Info().Msg("Outbox relay stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce публикует одну пачку неотправленных событий и отмечает их
+// отправленными. Возвращает число опубликованных событий. Строки блокируются
+// FOR UPDATE SKIP LOCKED, поэтому несколько экземпляров relay не публикуют
+// одно событие одновременно.
+func (o *Outbox) RelayOnce(ctx context.Context) (int, error) {
+	tx, err := o.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query := fmt.Sprintf(
+		"SELECT id, event_id, envelope FROM %s WHERE sent_at IS NULL ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED",
+		o.table,
+	)
+	rows, err := tx.Query(ctx, query, o.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select events: %w", err)
+	}
+
+	type event struct {
+		id       int64
+		eventID  string
+		envelope []byte
+	}
+	var events []event
+	for rows.Next() {
+		var e event
+		if err := rows.Scan(&e.id, &e.eventID, &e.envelope); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read events: %w", err)
+	}
+
+	update := fmt.Sprintf("UPDATE %s SET sent_at = now() WHERE id = $1 AND sent_at IS NULL", o.table)
+	sent := 0
+	var publishErr error
+	for _, e := range events {
+		if err := o.producer.Publish(ctx, o.config.Topic, e.eventID, e.envelope); err != nil {
+			publishErr = fmt.Errorf("failed to publish event %s: %w", e.eventID, err)
+			break
+		}
+		if _, err := tx.Exec(ctx, update, e.id); err != nil {
+			publishErr = fmt.Errorf("failed to mark event %s as sent: %w", e.eventID, err)
+			break
+		}
+		sent++
+	}
+
+	// Фиксируем отметки об уже опубликованных событиях даже при ошибке
+	if err := tx.Commit(ctx); err != nil {
+		return 0, errors.Join(publishErr, fmt.Errorf("failed to commit transaction: %w", err))
+	}
+	return sent, publishErr
+}
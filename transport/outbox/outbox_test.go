@@ -0,0 +1,184 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	json "github.com/bytedance/sonic"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gitlab.com/zynero/shared/transport"
+)
+
+type execCall struct {
+	sql  string
+	args []any
+}
+
+// fakeTx записывает Exec и отдает строки rows на Query
+type fakeTx struct {
+	pgx.Tx
+	rows      [][]any
+	execs     []execCall
+	execErr   error
+	committed bool
+}
+
+func (tx *fakeTx) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tx.execs = append(tx.execs, execCall{sql: sql, args: args})
+	return pgconn.NewCommandTag("UPDATE 1"), tx.execErr
+}
+
+func (tx *fakeTx) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return &fakeRows{rows: tx.rows, pos: -1}, nil
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(context.Context) error { return nil }
+
+type fakeRows struct {
+	pgx.Rows
+	rows [][]any
+	pos  int
+}
+
+func (r *fakeRows) Next() bool {
+	r.pos++
+	return r.pos < len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	row := r.rows[r.pos]
+	*dest[0].(*int64) = row[0].(int64)
+	*dest[1].(*string) = row[1].(string)
+	*dest[2].(*[]byte) = row[2].([]byte)
+	return nil
+}
+
+func (r *fakeRows) Close()     {}
+func (r *fakeRows) Err() error { return nil }
+
+type fakePool struct {
+	tx *fakeTx
+}
+
+func (p *fakePool) Begin(context.Context) (pgx.Tx, error) {
+	return p.tx, nil
+}
+
+// fakeProducer отклоняет публикацию ключей из fail
+type fakeProducer struct {
+	fail      map[string]bool
+	published []string
+}
+
+func (p *fakeProducer) Publish(_ context.Context, _, key string, _ []byte) error {
+	if p.fail[key] {
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, key)
+	return nil
+}
+
+func (p *fakeProducer) Close() error { return nil }
+
+func TestEnqueue(t *testing.T) {
+	ob := New(nil, &fakeProducer{}, Config{Table: "orders_outbox"})
+	tx := &fakeTx{}
+
+	if err := ob.Enqueue(context.Background(), tx, "order.created", map[string]int{"id": 42}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if len(tx.execs) != 1 {
+		t.Fatalf("Exec called %d times, want 1", len(tx.execs))
+	}
+
+	call := tx.execs[0]
+	wantSQL := `INSERT INTO "orders_outbox" (event_id, event_type, envelope) VALUES ($1, $2, $3)`
+	if call.sql != wantSQL {
+		t.Errorf("sql = %q, want %q", call.sql, wantSQL)
+	}
+	if call.args[1] != "order.created" {
+		t.Errorf("event_type = %v, want order.created", call.args[1])
+	}
+
+	var envelope transport.Envelope
+	if err := json.Unmarshal(call.args[2].([]byte), &envelope); err != nil {
+		t.Fatalf("failed to unmarshal envelope: %v", err)
+	}
+	if envelope.EventID == "" || envelope.EventID != call.args[0] {
+		t.Errorf("envelope event_id = %q, column event_id = %v", envelope.EventID, call.args[0])
+	}
+	if envelope.EventType != "order.created" || string(envelope.Payload) != `{"id":42}` {
+		t.Errorf("envelope = %+v", envelope)
+	}
+}
+
+func TestRelayOnce(t *testing.T) {
+	tx := &fakeTx{rows: [][]any{
+		{int64(1), "e1", []byte(`{}`)},
+		{int64(2), "e2", []byte(`{}`)},
+	}}
+	producer := &fakeProducer{}
+	ob := New(&fakePool{tx: tx}, producer, Config{})
+
+	sent, err := ob.RelayOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RelayOnce() error = %v", err)
+	}
+	if sent != 2 || len(producer.published) != 2 {
+		t.Errorf("sent = %d, published = %v, want 2 events", sent, producer.published)
+	}
+	if len(tx.execs) != 2 || !tx.committed {
+		t.Errorf("updates = %d, committed = %v, want 2 updates and commit", len(tx.execs), tx.committed)
+	}
+}
+
+func TestRelayOncePartialFailure(t *testing.T) {
+	tx := &fakeTx{rows: [][]any{
+		{int64(1), "e1", []byte(`{}`)},
+		{int64(2), "e2", []byte(`{}`)},
+		{int64(3), "e3", []byte(`{}`)},
+	}}
+	producer := &fakeProducer{fail: map[string]bool{"e2": true}}
+	ob := New(&fakePool{tx: tx}, producer, Config{})
+
+	sent, err := ob.RelayOnce(context.Background())
+	if err == nil {
+		t.Fatal("RelayOnce() error = nil, want publish error")
+	}
+	if sent != 1 {
+		t.Errorf("sent = %d, want 1", sent)
+	}
+	// Отмечено только опубликованное событие, и отметка зафиксирована
+	if len(tx.execs) != 1 || tx.execs[0].args[0] != int64(1) {
+		t.Errorf("updates = %+v, want only id 1", tx.execs)
+	}
+	if !tx.committed {
+		t.Error("transaction not committed after partial failure")
+	}
+	if len(producer.published) != 1 || producer.published[0] != "e1" {
+		t.Errorf("published = %v, want [e1]", producer.published)
+	}
+}
+
+func TestRelayOnceMarkFailure(t *testing.T) {
+	tx := &fakeTx{
+		rows:    [][]any{{int64(1), "e1", []byte(`{}`)}},
+		execErr: errors.New("connection lost"),
+	}
+	ob := New(&fakePool{tx: tx}, &fakeProducer{}, Config{})
+
+	sent, err := ob.RelayOnce(context.Background())
+	if err == nil || sent != 0 {
+		t.Errorf("RelayOnce() = %d, %v, want 0 and mark error", sent, err)
+	}
+	if !tx.committed {
+		t.Error("transaction not committed after mark failure")
+	}
+}
@@ -10,3 +10,9 @@ type Producer interface {
 	Publish(ctx context.Context, topic string, key string, value []byte) error
 	io.Closer // Добавляем интерфейс для graceful shutdown
 }
+
+// HeaderProducer определяет producer, поддерживающий заголовки сообщений
+type HeaderProducer interface {
+	Producer
+	PublishWithHeaders(ctx context.Context, topic string, key string, value []byte, headers map[string]string) error
+}
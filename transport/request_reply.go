@@ -0,0 +1,158 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// ReplyToHeader заголовок с топиком, в который ожидается ответ
+	ReplyToHeader = "reply_to"
+	// CorrelationIDHeader заголовок, связывающий ответ с запросом
+	CorrelationIDHeader = "correlation_id"
+)
+
+// ErrReplyTimeout возвращается, если ответ не получен за отведенное время
+var ErrReplyTimeout = errors.New("reply timeout")
+
+// RequestReply реализует синхронный запрос-ответ поверх Producer и Consumer.
+// Request публикует событие с заголовками ReplyToHeader и CorrelationIDHeader
+// и ждет ответ с тем же correlation ID. Ответы принимает сам RequestReply как
+// Handler consumer'а топика ответов:
+//
+//	rr := transport.NewRequestReply(producer, "orders.replies", 5*time.Second)
+//	replies, err := kafka.NewConsumer(cfg, "orders.replies", rr)
+//
+// Отвечающая сторона публикует ответ через Reply.
+type RequestReply struct {
+	producer   HeaderProducer
+	replyTopic string
+	timeout    time.Duration
+
+	mu      sync.Mutex
+	pending map[string]chan Envelope
+}
+
+// NewRequestReply создает RequestReply. timeout ограничивает ожидание ответа
+// в дополнение к дедлайну ctx; 0 отключает собственный таймаут.
+func NewRequestReply(producer HeaderProducer, replyTopic string, timeout time.Duration) *RequestReply {
+	return &RequestReply{
+		producer:   producer,
+		replyTopic: replyTopic,
+		timeout:    timeout,
+		pending:    make(map[string]chan Envelope),
+	}
+}
+
+// Request публикует событие eventType в topic и ждет ответ. Если ответ не
+// пришел за timeout, возвращается ошибка, оборачивающая ErrReplyTimeout.
+// При отмене ctx ожидание прекращается и возвращается ctx.Err().
+func (rr *RequestReply) Request(ctx context.Context, topic, eventType string, payload any) (Envelope, error) {
+	correlationID := uuid.NewString()
+	headers := map[string]string{
+		ReplyToHeader:       rr.replyTopic,
+		CorrelationIDHeader: correlationID,
+	}
+
+	value, err := marshalEnvelope(eventType, payload, headers)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	// Ожидающий регистрируется до публикации, чтобы не потерять быстрый ответ
+	replyCh := make(chan Envelope, 1)
+	rr.mu.Lock()
+	rr.pending[correlationID] = replyCh
+	rr.mu.Unlock()
+	defer rr.forget(correlationID)
+
+	waitCtx := ctx
+	if rr.timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, rr.timeout)
+		defer cancel()
+	}
+
+	if err := rr.producer.PublishWithHeaders(waitCtx, topic, correlationID, value, headers); err != nil {
+		return Envelope{}, fmt.Errorf("failed to publish request: %w", err)
+	}
+
+	select {
+	case reply := <-replyCh:
+		return reply, nil
+	case <-waitCtx.Done():
+		if ctx.Err() != nil {
+			return Envelope{}, ctx.Err()
+		}
+		return Envelope{}, fmt.Errorf("%w after %s: correlation id %s", ErrReplyTimeout, rr.timeout, correlationID)
+	}
+}
+
+// Handle передает ответ ожидающему запросу. Ответы без ожидающего запроса
+// (например, пришедшие после таймаута) пропускаются.
+func (rr *RequestReply) Handle(_ context.Context, envelope Envelope) error {
+	correlationID := envelope.Headers[CorrelationIDHeader]
+	if correlationID == "" {
+		return nil
+	}
+
+	rr.mu.Lock()
+	replyCh, ok := rr.pending[correlationID]
+	delete(rr.pending, correlationID)
+	rr.mu.Unlock()
+
+	if ok {
+		replyCh <- envelope
+	}
+	return nil
+}
+
+// forget удаляет ожидающий запрос
+func (rr *RequestReply) forget(correlationID string) {
+	rr.mu.Lock()
+	delete(rr.pending, correlationID)
+	rr.mu.Unlock()
+}
+
+// Reply публикует ответ на запрос request в топик из его ReplyToHeader
+func Reply(ctx context.Context, producer HeaderProducer, request Envelope, eventType string, payload any) error {
+	replyTo := request.Headers[ReplyToHeader]
+	correlationID := request.Headers[CorrelationIDHeader]
+	if replyTo == "" || correlationID == "" {
+		return NewNonRetryableError(fmt.Errorf("event %s is not a request: missing %s or %s header", request.EventID, ReplyToHeader, CorrelationIDHeader))
+	}
+
+	headers := map[string]string{CorrelationIDHeader: correlationID}
+	value, err := marshalEnvelope(eventType, payload, headers)
+	if err != nil {
+		return err
+	}
+	return producer.PublishWithHeaders(ctx, replyTo, correlationID, value, headers)
+}
+
+// marshalEnvelope сериализует payload в Envelope с заголовками
+func marshalEnvelope(eventType string, payload any, headers map[string]string) ([]byte, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	value, err := json.Marshal(Envelope{
+		EventID:       uuid.NewString(),
+		EventType:     eventType,
+		OccurredAt:    time.Now().UTC(),
+		Payload:       payloadBytes,
+		SchemaVersion: DefaultSchemaVersion,
+		ContentType:   "application/json",
+		Headers:       headers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+	return value, nil
+}
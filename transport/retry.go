@@ -1,7 +1,10 @@
 package transport
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math/rand/v2"
 	"time"
 )
 
@@ -25,6 +28,71 @@ func DefaultRetryPolicy() RetryPolicy {
 	}
 }
 
+// Backoff возвращает задержку перед повторной попыткой с номером attempt (с нуля).
+// Задержка растет экспоненциально от BaseDelay с множителем BackoffFactor и
+// ограничена MaxDelay. При включенном Jitter случайно уменьшается до половины.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < attempt; i++ {
+		delay = time.Duration(float64(delay) * p.BackoffFactor)
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			delay = p.MaxDelay
+			break
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter && delay > 1 {
+		half := delay / 2
+		delay = half + time.Duration(rand.Int64N(int64(half)+1))
+	}
+	return delay
+}
+
+// Execute вызывает fn, повторяя вызов при ошибке не более MaxRetries раз с
+// задержкой Backoff. Если ошибка реализует RetryableError и задает
+// RetryAfter, используется эта задержка. Повторы прекращаются при
+// неповторяемой ошибке или отмене ctx. Последняя ошибка возвращается
+// с указанием числа попыток.
+func (p RetryPolicy) Execute(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if !IsRetryableError(err) || attempt >= p.MaxRetries {
+			return fmt.Errorf("after %d attempts: %w", attempt+1, err)
+		}
+
+		delay := p.Backoff(attempt)
+		var retryableErr RetryableError
+		if errors.As(err, &retryableErr) && retryableErr.RetryAfter() > 0 {
+			delay = retryableErr.RetryAfter()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("after %d attempts: %w", attempt+1, errors.Join(ctx.Err(), err))
+		case <-timer.C:
+		}
+	}
+}
+
+// Retry выполняет fn по политике policy (см. RetryPolicy.Execute). Подходит
+// для HTTP, БД и других вызовов вне Kafka. Если ctx уже отменен, fn не
+// вызывается.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return policy.Execute(ctx, func() error {
+		return fn(ctx)
+	})
+}
+
 // RetryableError определяет интерфейс для ошибок с информацией о возможности retry
 type RetryableError interface {
 	error
@@ -32,6 +100,25 @@ type RetryableError interface {
 	RetryAfter() time.Duration
 }
 
+// Retryable помечает err как повторяемую с задержкой из политики retry.
+// Вместе с Permanent позволяет обработчикам управлять повторами и DLQ, не
+// завися от пакета конкретного транспорта. Для nil возвращает nil.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return NewTemporaryError(err, 0)
+}
+
+// Permanent помечает err как неповторяемую: сообщение сразу уходит в DLQ.
+// Для nil возвращает nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return NewNonRetryableError(err)
+}
+
 // NonRetryableError создает ошибку, которая не должна повторяться
 func NewNonRetryableError(err error) error {
 	return &nonRetryableError{err: err}
@@ -0,0 +1,60 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ErrCodeUnknownEventType код HandlerError, который Router возвращает для типа
+// события без зарегистрированного обработчика и без fallback
+const ErrCodeUnknownEventType = "unknown_event_type"
+
+// Router направляет конверты обработчикам по Envelope.EventType. Router сам
+// реализует Handler, поэтому его можно передать прямо в конструктор consumer.
+type Router struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	fallback Handler
+}
+
+// NewRouter создает пустой Router
+func NewRouter() *Router {
+	return &Router{
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Register устанавливает обработчик для eventType, заменяя предыдущий. Метод
+// не называется Handle, потому что Router сам реализует Handler.
+func (r *Router) Register(eventType string, h Handler) *Router {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[eventType] = h
+	return r
+}
+
+// SetFallback устанавливает обработчик для типов событий без своего обработчика
+func (r *Router) SetFallback(h Handler) *Router {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = h
+	return r
+}
+
+// Handle направляет конверт по типу события. Без подходящего обработчика и
+// fallback возвращает неповторяемую HandlerError с кодом
+// ErrCodeUnknownEventType, и сообщение уходит в DLQ.
+func (r *Router) Handle(ctx context.Context, envelope Envelope) error {
+	r.mu.RLock()
+	h, ok := r.handlers[envelope.EventType]
+	if !ok {
+		h = r.fallback
+	}
+	r.mu.RUnlock()
+
+	if h == nil {
+		return NewHandlerError(ErrCodeUnknownEventType, false, fmt.Errorf("no handler for event type %q", envelope.EventType))
+	}
+	return h.Handle(ctx, envelope)
+}
@@ -0,0 +1,64 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRouterDispatchesByEventType(t *testing.T) {
+	created := &countingHandler{}
+	cancelled := &countingHandler{err: errors.New("boom")}
+	router := NewRouter().
+		Register("order.created", created).
+		Register("order.cancelled", cancelled)
+
+	if err := router.Handle(context.Background(), Envelope{EventType: "order.created"}); err != nil {
+		t.Fatalf("Handle(order.created) error = %v", err)
+	}
+	// Ошибка обработчика возвращается без изменений
+	if err := router.Handle(context.Background(), Envelope{EventType: "order.cancelled"}); !errors.Is(err, cancelled.err) {
+		t.Fatalf("Handle(order.cancelled) error = %v, want handler error", err)
+	}
+	if created.calls != 1 || cancelled.calls != 1 {
+		t.Errorf("calls = created %d, cancelled %d, want 1 each", created.calls, cancelled.calls)
+	}
+
+	// Повторная регистрация заменяет обработчик
+	replacement := &countingHandler{}
+	router.Register("order.created", replacement)
+	_ = router.Handle(context.Background(), Envelope{EventType: "order.created"})
+	if created.calls != 1 || replacement.calls != 1 {
+		t.Errorf("calls = old %d, new %d, want the new handler only", created.calls, replacement.calls)
+	}
+}
+
+func TestRouterUnknownEventType(t *testing.T) {
+	router := NewRouter().Register("order.created", &countingHandler{})
+
+	err := router.Handle(context.Background(), Envelope{EventType: "order.shipped"})
+	var handlerErr *HandlerError
+	if !errors.As(err, &handlerErr) {
+		t.Fatalf("Handle() error = %v, want HandlerError", err)
+	}
+	if handlerErr.Code != ErrCodeUnknownEventType || handlerErr.IsRetryable() {
+		t.Errorf("error = %+v, want non-retryable %s", handlerErr, ErrCodeUnknownEventType)
+	}
+}
+
+func TestRouterFallback(t *testing.T) {
+	created := &countingHandler{}
+	fallback := &countingHandler{}
+	router := NewRouter().
+		Register("order.created", created).
+		SetFallback(fallback)
+
+	for _, eventType := range []string{"order.shipped", "", "order.created"} {
+		if err := router.Handle(context.Background(), Envelope{EventType: eventType}); err != nil {
+			t.Fatalf("Handle(%q) error = %v", eventType, err)
+		}
+	}
+	if fallback.calls != 2 || created.calls != 1 {
+		t.Errorf("calls = fallback %d, created %d, want 2 and 1", fallback.calls, created.calls)
+	}
+}